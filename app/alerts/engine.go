@@ -0,0 +1,296 @@
+package alerts
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Estados de um alerta
+const (
+	StatePending  = "pending"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// Alert é uma instância de alerta de uma regra em uma estação
+type Alert struct {
+	ID         int64   `json:"id"`
+	RuleID     string  `json:"rule_id"`
+	RuleName   string  `json:"rule_name"`
+	Station    string  `json:"station"`
+	State      string  `json:"state"`
+	Severity   string  `json:"severity"`
+	Metric     string  `json:"metric"`
+	Value      float64 `json:"value"`
+	Threshold  float64 `json:"threshold"`
+	StartedAt  int64   `json:"started_at"`
+	FiredAt    *int64  `json:"fired_at,omitempty"`
+	ResolvedAt *int64  `json:"resolved_at,omitempty"`
+}
+
+// alertKey identifica o alerta ativo de uma regra em uma estação
+type alertKey struct {
+	rule    string
+	station string
+}
+
+// Engine avalia as regras a cada leitura e mantém a máquina de estados
+// pending -> firing -> resolved de cada par regra/estação
+type Engine struct {
+	mu       sync.Mutex
+	rules    []Rule
+	active   map[alertKey]*Alert
+	loaded   bool
+	onChange []func(Alert)
+}
+
+// NewEngine cria um motor de alertas com as regras informadas
+func NewEngine(rules []Rule) *Engine {
+	return &Engine{rules: rules, active: make(map[alertKey]*Alert)}
+}
+
+var defaultEngine = NewEngine(nil)
+
+// Setup carrega as regras de ALERT_RULES_FILE (padrão alertas.json) no
+// motor padrão, aceitando apenas regras sobre as métricas informadas. Sem
+// arquivo, ou com alguma regra inválida, nenhuma regra é avaliada
+func Setup(metrics []string) {
+	path := os.Getenv("ALERT_RULES_FILE")
+	if path == "" {
+		path = "alertas.json"
+	}
+
+	rules, err := LoadRules(path)
+	if err == nil {
+		err = CheckMetrics(rules, metrics)
+	}
+	if err != nil {
+		slog.Warn("Regras de alerta não carregadas", "file", path, "err", err)
+		return
+	}
+	defaultEngine.SetRules(rules)
+	slog.Info("Regras de alerta carregadas", "file", path, "rules", len(rules))
+}
+
+// Default retorna o motor de alertas usado pela ingestão
+func Default() *Engine {
+	return defaultEngine
+}
+
+// SetRules substitui as regras avaliadas pelo motor
+func (e *Engine) SetRules(rules []Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = rules
+}
+
+// AddRule acrescenta uma regra, substituindo outra com o mesmo id
+func (e *Engine) AddRule(rule Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range e.rules {
+		if e.rules[i].ID == rule.ID {
+			e.rules[i] = rule
+			return
+		}
+	}
+	e.rules = append(e.rules, rule)
+}
+
+// Rules retorna as regras configuradas
+func (e *Engine) Rules() []Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Rule(nil), e.rules...)
+}
+
+// OnChange registra uma função chamada quando um alerta dispara ou é resolvido
+func (e *Engine) OnChange(fn func(Alert)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onChange = append(e.onChange, fn)
+}
+
+// Evaluate aplica as regras às métricas de uma leitura da estação no
+// instante timestamp, persistindo as transições de estado
+func (e *Engine) Evaluate(db *sql.DB, station string, timestamp int64, metrics map[string]float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.loaded {
+		if err := e.loadActive(db); err != nil {
+			slog.Error("Erro ao carregar alertas ativos", "err", err)
+			return
+		}
+		e.loaded = true
+	}
+
+	var changed []Alert
+	for _, rule := range e.rules {
+		value, ok := metrics[rule.Metric]
+		if !ok || !rule.AppliesTo(station) {
+			continue
+		}
+
+		key := alertKey{rule: rule.ID, station: station}
+		alert, transition, err := e.step(db, rule, key, value, timestamp)
+		if err != nil {
+			slog.Error("Erro ao persistir alerta", "rule", rule.ID, "station", station, "err", err)
+			continue
+		}
+		if transition {
+			slog.Info("Alerta atualizado", "rule", rule.ID, "station", station,
+				"state", alert.State, "severity", alert.Severity, "value", value)
+			changed = append(changed, alert)
+		}
+	}
+
+	for _, alert := range changed {
+		for _, fn := range e.onChange {
+			fn(alert)
+		}
+	}
+}
+
+// step avança a máquina de estados de um par regra/estação. Retorna o
+// alerta e se houve disparo ou resolução
+func (e *Engine) step(db *sql.DB, rule Rule, key alertKey, value float64, timestamp int64) (Alert, bool, error) {
+	alert, active := e.active[key]
+
+	switch {
+	case !active && rule.Matches(value):
+		alert = &Alert{
+			RuleID:    rule.ID,
+			RuleName:  rule.Name,
+			Station:   key.station,
+			State:     StatePending,
+			Severity:  rule.Severity,
+			Metric:    rule.Metric,
+			Value:     value,
+			Threshold: rule.Threshold,
+			StartedAt: timestamp,
+		}
+		if err := insertAlert(db, alert); err != nil {
+			return Alert{}, false, err
+		}
+		e.active[key] = alert
+		return e.fireIfDue(db, rule, alert, timestamp)
+
+	case !active:
+		return Alert{}, false, nil
+
+	case alert.State == StatePending && !rule.Matches(value):
+		// A condição não se manteve pelo tempo exigido
+		delete(e.active, key)
+		return Alert{}, false, deleteAlert(db, alert.ID)
+
+	case alert.State == StatePending:
+		alert.Value = value
+		return e.fireIfDue(db, rule, alert, timestamp)
+
+	case alert.State == StateFiring && rule.Clears(value):
+		alert.State = StateResolved
+		alert.Value = value
+		alert.ResolvedAt = &timestamp
+		delete(e.active, key)
+		return *alert, true, updateAlert(db, alert)
+
+	default:
+		alert.Value = value
+		return *alert, false, updateAlert(db, alert)
+	}
+}
+
+// fireIfDue dispara o alerta pendente se a condição já durou o tempo da regra
+func (e *Engine) fireIfDue(db *sql.DB, rule Rule, alert *Alert, timestamp int64) (Alert, bool, error) {
+	if time.Duration(timestamp-alert.StartedAt)*time.Second < rule.For {
+		return *alert, false, updateAlert(db, alert)
+	}
+	alert.State = StateFiring
+	alert.FiredAt = &timestamp
+	return *alert, true, updateAlert(db, alert)
+}
+
+// loadActive recupera do banco os alertas pendentes e disparados
+func (e *Engine) loadActive(db *sql.DB) error {
+	alerts, err := LoadAlerts(db, []string{StatePending, StateFiring}, 0)
+	if err != nil {
+		return err
+	}
+	for i := range alerts {
+		alert := alerts[i]
+		e.active[alertKey{rule: alert.RuleID, station: alert.Station}] = &alert
+	}
+	return nil
+}
+
+func insertAlert(db *sql.DB, a *Alert) error {
+	result, err := db.Exec(`
+        INSERT INTO alerts (
+            rule_id, rule_name, station, state, severity, metric, value,
+            threshold, started_at, fired_at, resolved_at, updated_at
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `, a.RuleID, a.RuleName, a.Station, a.State, a.Severity, a.Metric, a.Value,
+		a.Threshold, a.StartedAt, a.FiredAt, a.ResolvedAt, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	a.ID, err = result.LastInsertId()
+	return err
+}
+
+func updateAlert(db *sql.DB, a *Alert) error {
+	_, err := db.Exec(`
+        UPDATE alerts
+        SET state = ?, value = ?, fired_at = ?, resolved_at = ?, updated_at = ?
+        WHERE id = ?
+    `, a.State, a.Value, a.FiredAt, a.ResolvedAt, time.Now().Unix(), a.ID)
+	return err
+}
+
+func deleteAlert(db *sql.DB, id int64) error {
+	_, err := db.Exec("DELETE FROM alerts WHERE id = ?", id)
+	return err
+}
+
+// LoadAlerts lista os alertas nos estados informados, do mais recente para
+// o mais antigo. limit 0 retorna todos
+func LoadAlerts(db *sql.DB, states []string, limit int) ([]Alert, error) {
+	query := `
+        SELECT id, rule_id, rule_name, station, state, severity, metric, value,
+               threshold, started_at, fired_at, resolved_at
+        FROM alerts`
+	args := []interface{}{}
+	if len(states) > 0 {
+		query += " WHERE state IN (?" + strings.Repeat(", ?", len(states)-1) + ")"
+		for _, s := range states {
+			args = append(args, s)
+		}
+	}
+	query += " ORDER BY started_at DESC"
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []Alert{}
+	for rows.Next() {
+		var a Alert
+		if err := rows.Scan(&a.ID, &a.RuleID, &a.RuleName, &a.Station, &a.State, &a.Severity,
+			&a.Metric, &a.Value, &a.Threshold, &a.StartedAt, &a.FiredAt, &a.ResolvedAt); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
@@ -0,0 +1,214 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severidades aceitas nas regras
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Rule é uma regra de alerta sobre uma métrica da leitura, por exemplo
+// "wind_speed_kmh > 62 for 10m"
+type Rule struct {
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Expr       string        `json:"expr"`
+	Severity   string        `json:"severity"`
+	Hysteresis float64       `json:"hysteresis"`
+	Stations   []string      `json:"stations"` // vazio aplica a todas
+	Metric     string        `json:"-"`
+	Operator   string        `json:"-"`
+	Threshold  float64       `json:"-"`
+	For        time.Duration `json:"-"`
+}
+
+// Matches indica se o valor satisfaz a condição da regra
+func (r Rule) Matches(value float64) bool {
+	switch r.Operator {
+	case ">":
+		return value > r.Threshold
+	case ">=":
+		return value >= r.Threshold
+	case "<":
+		return value < r.Threshold
+	case "<=":
+		return value <= r.Threshold
+	case "==":
+		return value == r.Threshold
+	case "!=":
+		return value != r.Threshold
+	}
+	return false
+}
+
+// Clears indica se um alerta disparado pode ser resolvido. A histerese
+// exige que o valor se afaste do limiar antes da resolução, evitando que
+// oscilações em torno dele gerem alertas repetidos
+func (r Rule) Clears(value float64) bool {
+	switch r.Operator {
+	case ">", ">=":
+		return value < r.Threshold-r.Hysteresis
+	case "<", "<=":
+		return value > r.Threshold+r.Hysteresis
+	}
+	return !r.Matches(value)
+}
+
+// AppliesTo indica se a regra vale para a estação
+func (r Rule) AppliesTo(station string) bool {
+	if len(r.Stations) == 0 {
+		return true
+	}
+	for _, s := range r.Stations {
+		if s == station {
+			return true
+		}
+	}
+	return false
+}
+
+// parseExpr interpreta "<métrica> <operador> <valor> [for <duração>]"
+func (r *Rule) parseExpr() error {
+	fields := strings.Fields(r.Expr)
+	if len(fields) != 3 && len(fields) != 5 && len(fields) != 6 {
+		return fmt.Errorf("expressão inválida: %q", r.Expr)
+	}
+
+	r.Metric = fields[0]
+	r.Operator = fields[1]
+	if !r.validOperator() {
+		return fmt.Errorf("operador inválido: %q", r.Operator)
+	}
+
+	threshold, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return fmt.Errorf("limiar inválido: %q", fields[2])
+	}
+	r.Threshold = threshold
+
+	if len(fields) > 3 {
+		if fields[3] != "for" {
+			return fmt.Errorf("esperado \"for\" em %q", r.Expr)
+		}
+		r.For, err = parseDuration(fields[4:])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Rule) validOperator() bool {
+	switch r.Operator {
+	case ">", ">=", "<", "<=", "==", "!=":
+		return true
+	}
+	return false
+}
+
+// parseDuration aceita durações do Go ("10m") ou número e unidade por
+// extenso ("10 minutes", "2 horas")
+func parseDuration(fields []string) (time.Duration, error) {
+	if len(fields) == 1 {
+		d, err := time.ParseDuration(fields[0])
+		if err != nil {
+			return 0, fmt.Errorf("duração inválida: %q", fields[0])
+		}
+		return d, nil
+	}
+
+	n, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("duração inválida: %q", strings.Join(fields, " "))
+	}
+	var unit time.Duration
+	switch strings.ToLower(fields[1]) {
+	case "s", "sec", "second", "seconds", "segundo", "segundos":
+		unit = time.Second
+	case "m", "min", "minute", "minutes", "minuto", "minutos":
+		unit = time.Minute
+	case "h", "hour", "hours", "hora", "horas":
+		unit = time.Hour
+	default:
+		return 0, fmt.Errorf("unidade de duração inválida: %q", fields[1])
+	}
+	return time.Duration(n * float64(unit)), nil
+}
+
+// ParseRules lê e valida uma lista de regras em JSON
+func ParseRules(content []byte) ([]Rule, error) {
+	var rules []Rule
+	if err := json.Unmarshal(content, &rules); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar regras: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range rules {
+		rule := &rules[i]
+		if rule.ID == "" {
+			return nil, fmt.Errorf("regra %d sem id", i+1)
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("id de regra repetido: %s", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		if err := rule.parseExpr(); err != nil {
+			return nil, fmt.Errorf("regra %s: %w", rule.ID, err)
+		}
+		switch rule.Severity {
+		case "":
+			rule.Severity = SeverityWarning
+		case SeverityInfo, SeverityWarning, SeverityCritical:
+		default:
+			return nil, fmt.Errorf("regra %s: severidade inválida: %q", rule.ID, rule.Severity)
+		}
+		if rule.Name == "" {
+			rule.Name = rule.Expr
+		}
+	}
+	return rules, nil
+}
+
+// CheckMetrics confere se todas as regras usam métricas conhecidas, para que
+// um nome digitado errado não deixe a regra silenciosamente sem efeito
+func CheckMetrics(rules []Rule, metrics []string) error {
+	known := make(map[string]bool, len(metrics))
+	for _, m := range metrics {
+		known[m] = true
+	}
+	for _, rule := range rules {
+		if !known[rule.Metric] {
+			return fmt.Errorf("regra %s: métrica desconhecida: %q", rule.ID, rule.Metric)
+		}
+	}
+	return nil
+}
+
+// NewRule cria e valida uma regra a partir da expressão
+func NewRule(id, name, expr, severity string) (Rule, error) {
+	rules, err := ParseRules([]byte(fmt.Sprintf(`[{"id": %q, "name": %q, "expr": %q, "severity": %q}]`,
+		id, name, expr, severity)))
+	if err != nil {
+		return Rule{}, err
+	}
+	return rules[0], nil
+}
+
+// LoadRules lê as regras do arquivo JSON indicado
+func LoadRules(path string) ([]Rule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(content)
+}
@@ -0,0 +1,482 @@
+// Package broker implementa um broker MQTT 3.1.1 embutido, para instalações
+// que não querem depender de um Mosquitto externo: a estação publica
+// diretamente no processo do servidor. Suporta vários listeners TCP,
+// autenticação por usuário e senha, mensagens retidas, curingas + e #,
+// QoS 0 e 1 na entrega (QoS 2 é aceito na publicação e entregue como 1),
+// keepalive e mensagens de última vontade. As sessões são sempre limpas:
+// assinaturas e mensagens pendentes não sobrevivem a uma desconexão
+package broker
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// LocalUser é o usuário interno aceito pelo broker para o cliente do
+// próprio processo, com senha gerada a cada inicialização
+const LocalUser = "_local"
+
+// connectTimeout é o prazo para o cliente enviar o CONNECT após abrir a conexão
+const connectTimeout = 10 * time.Second
+
+// connectPacketLimit é o tamanho máximo do CONNECT, lido antes da
+// autenticação. Basta para identificador, credenciais e uma última vontade curta
+const connectPacketLimit = 8 * 1024
+
+// DefaultMaxPacketSize é o tamanho máximo padrão dos pacotes após a conexão
+const DefaultMaxPacketSize = 256 * 1024
+
+// outboxSize é o limite de pacotes aguardando envio por cliente; mensagens
+// para clientes lentos além desse limite são descartadas
+const outboxSize = 1024
+
+// Config descreve o broker embutido
+type Config struct {
+	Listeners      []string          // endereços TCP, como ":1883"
+	Users          map[string]string // usuário -> senha; vazio aceita conexões anônimas
+	MaxPacketSize  int               // bytes após o cabeçalho fixo (0 usa DefaultMaxPacketSize)
+	AllowAnonymous bool              // aceita listeners fora da loopback sem usuários
+}
+
+// ConfigFromEnv lê a configuração de MQTT_EMBEDDED_LISTEN (endereços separados
+// por vírgula), MQTT_EMBEDDED_USERS (pares usuario:senha separados por
+// vírgula), MQTT_EMBEDDED_MAX_PACKET (bytes) e MQTT_EMBEDDED_ALLOW_ANONYMOUS
+// (true ou false). enabled é falso quando MQTT_EMBEDDED_LISTEN está vazio
+func ConfigFromEnv() (cfg Config, enabled bool, err error) {
+	for _, addr := range strings.Split(os.Getenv("MQTT_EMBEDDED_LISTEN"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			cfg.Listeners = append(cfg.Listeners, addr)
+		}
+	}
+	if value := os.Getenv("MQTT_EMBEDDED_MAX_PACKET"); value != "" {
+		if cfg.MaxPacketSize, err = strconv.Atoi(value); err != nil || cfg.MaxPacketSize <= 0 {
+			return cfg, false, fmt.Errorf("MQTT_EMBEDDED_MAX_PACKET inválido: %q", value)
+		}
+	}
+	if value := os.Getenv("MQTT_EMBEDDED_ALLOW_ANONYMOUS"); value != "" {
+		if cfg.AllowAnonymous, err = strconv.ParseBool(value); err != nil {
+			return cfg, false, fmt.Errorf("MQTT_EMBEDDED_ALLOW_ANONYMOUS inválido: %q", value)
+		}
+	}
+	cfg.Users, err = ParseUsers(os.Getenv("MQTT_EMBEDDED_USERS"))
+	return cfg, len(cfg.Listeners) > 0, err
+}
+
+// ParseUsers interpreta uma lista "usuario:senha,outro:senha"
+func ParseUsers(value string) (map[string]string, error) {
+	users := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		user, password, ok := strings.Cut(pair, ":")
+		if !ok || user == "" {
+			return nil, fmt.Errorf("usuário MQTT inválido: %q, use usuario:senha", pair)
+		}
+		if user == LocalUser {
+			return nil, fmt.Errorf("o usuário %s é reservado", LocalUser)
+		}
+		users[user] = password
+	}
+	return users, nil
+}
+
+// Broker é um broker MQTT em execução
+type Broker struct {
+	users         map[string]string
+	localPassword string
+	maxPacketSize int
+	listeners     []net.Listener
+
+	mu       sync.Mutex
+	clients  map[string]*client
+	retained map[string]*packets.PublishPacket
+	closed   bool
+	anon     int // sequência dos identificadores gerados
+
+	wg sync.WaitGroup
+}
+
+// Start abre os listeners e começa a aceitar conexões. Endereços com porta 0
+// escolhem uma porta livre, útil em testes; veja Addrs. Sem usuários, só
+// listeners na loopback são aceitos, a menos que AllowAnonymous esteja ligado
+func Start(cfg Config) (*Broker, error) {
+	if len(cfg.Listeners) == 0 {
+		return nil, errors.New("nenhum listener configurado")
+	}
+	if len(cfg.Users) == 0 {
+		for _, addr := range cfg.Listeners {
+			if loopback(addr) {
+				continue
+			}
+			if !cfg.AllowAnonymous {
+				return nil, fmt.Errorf("o listener MQTT %s aceita conexões de outras máquinas sem usuários; configure os usuários ou permita anônimos explicitamente", addr)
+			}
+			slog.Warn("Broker MQTT embutido aceitando conexões anônimas de outras máquinas", "addr", addr)
+		}
+	}
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	if cfg.MaxPacketSize <= 0 {
+		cfg.MaxPacketSize = DefaultMaxPacketSize
+	}
+	b := &Broker{
+		users:         cfg.Users,
+		localPassword: hex.EncodeToString(secret),
+		maxPacketSize: cfg.MaxPacketSize,
+		clients:       map[string]*client{},
+		retained:      map[string]*packets.PublishPacket{},
+	}
+	for _, addr := range cfg.Listeners {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			b.Close()
+			return nil, fmt.Errorf("erro ao abrir listener MQTT %s: %w", addr, err)
+		}
+		b.listeners = append(b.listeners, l)
+		slog.Info("Broker MQTT embutido escutando", "addr", l.Addr().String(), "auth", len(b.users) > 0)
+		b.wg.Add(1)
+		go b.accept(l)
+	}
+	return b, nil
+}
+
+// loopback indica se o endereço escuta apenas na interface de loopback
+func loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Addrs retorna os endereços efetivos dos listeners
+func (b *Broker) Addrs() []net.Addr {
+	addrs := make([]net.Addr, len(b.listeners))
+	for i, l := range b.listeners {
+		addrs[i] = l.Addr()
+	}
+	return addrs
+}
+
+// LocalURL retorna o endereço do primeiro listener, com as credenciais do
+// usuário interno, para o cliente MQTT do próprio processo. Listeners em
+// todas as interfaces são acessados pela de loopback
+func (b *Broker) LocalURL() string {
+	host, port := "127.0.0.1", "1883"
+	if addr, ok := b.listeners[0].Addr().(*net.TCPAddr); ok {
+		port = fmt.Sprint(addr.Port)
+		if !addr.IP.IsUnspecified() {
+			host = addr.IP.String()
+		}
+	}
+	u := url.URL{
+		Scheme: "tcp",
+		User:   url.UserPassword(LocalUser, b.localPassword),
+		Host:   net.JoinHostPort(host, port),
+	}
+	return u.String()
+}
+
+// Stats resume o estado do broker
+type Stats struct {
+	Clients  int `json:"clients"`
+	Retained int `json:"retained"`
+}
+
+// Stats retorna a quantidade de clientes conectados e de mensagens retidas
+func (b *Broker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Stats{Clients: len(b.clients), Retained: len(b.retained)}
+}
+
+// Close fecha os listeners e desconecta todos os clientes, sem publicar as
+// mensagens de última vontade
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	clients := make([]*client, 0, len(b.clients))
+	for _, c := range b.clients {
+		clients = append(clients, c)
+	}
+	b.mu.Unlock()
+
+	for _, l := range b.listeners {
+		l.Close()
+	}
+	for _, c := range clients {
+		c.setWill(nil)
+		c.close()
+	}
+	b.wg.Wait()
+	return nil
+}
+
+// accept recebe as conexões de um listener até ele ser fechado
+func (b *Broker) accept(l net.Listener) {
+	defer b.wg.Done()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Error("Erro ao aceitar conexão MQTT", "addr", l.Addr().String(), "err", err)
+			}
+			return
+		}
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			b.serve(conn)
+		}()
+	}
+}
+
+// authenticate confere as credenciais do CONNECT
+func (b *Broker) authenticate(cp *packets.ConnectPacket) bool {
+	if cp.Username == LocalUser && cp.UsernameFlag {
+		return subtle.ConstantTimeCompare(cp.Password, []byte(b.localPassword)) == 1
+	}
+	if len(b.users) == 0 {
+		return true
+	}
+	expected, ok := b.users[cp.Username]
+	if !cp.UsernameFlag || !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare(cp.Password, []byte(expected)) == 1
+}
+
+// serve atende uma conexão do CONNECT até a desconexão
+func (b *Broker) serve(conn net.Conn) {
+	defer conn.Close()
+	remote := conn.RemoteAddr().String()
+
+	conn.SetReadDeadline(time.Now().Add(connectTimeout))
+	packet, err := readPacket(conn, connectPacketLimit)
+	if err != nil {
+		slog.Debug("Conexão MQTT sem CONNECT", "remote", remote, "err", err)
+		return
+	}
+	cp, ok := packet.(*packets.ConnectPacket)
+	if !ok {
+		slog.Warn("Primeiro pacote MQTT não é CONNECT", "remote", remote)
+		return
+	}
+
+	connack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+	connack.ReturnCode = cp.Validate()
+	if connack.ReturnCode == packets.Accepted && !b.authenticate(cp) {
+		connack.ReturnCode = packets.ErrRefusedBadUsernameOrPassword
+	}
+	if connack.ReturnCode != packets.Accepted {
+		slog.Warn("Conexão MQTT recusada", "remote", remote, "client", cp.ClientIdentifier,
+			"user", cp.Username, "reason", packets.ConnackReturnCodes[connack.ReturnCode])
+		connack.Write(conn)
+		return
+	}
+
+	c := newClient(conn, cp)
+	if !b.register(c) {
+		return
+	}
+	defer b.unregister(c)
+	c.send(connack)
+	slog.Info("Cliente MQTT conectado", "remote", remote, "client", c.id, "user", cp.Username)
+
+	err = b.readLoop(c)
+	if err != nil {
+		slog.Info("Cliente MQTT desconectado", "client", c.id, "err", err)
+	} else {
+		slog.Info("Cliente MQTT desconectado", "client", c.id)
+	}
+}
+
+// register adiciona o cliente, desconectando outra conexão com o mesmo
+// identificador. Retorna falso se o broker já foi encerrado
+func (b *Broker) register(c *client) bool {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return false
+	}
+	if c.id == "" {
+		b.anon++
+		c.id = fmt.Sprintf("auto-%d", b.anon)
+	}
+	previous := b.clients[c.id]
+	b.clients[c.id] = c
+	b.mu.Unlock()
+
+	if previous != nil {
+		slog.Info("Cliente MQTT substituído por nova conexão", "client", c.id)
+		previous.close()
+	}
+	go c.writeLoop()
+	return true
+}
+
+// unregister remove o cliente e publica a mensagem de última vontade, se houver
+func (b *Broker) unregister(c *client) {
+	b.mu.Lock()
+	if b.clients[c.id] == c {
+		delete(b.clients, c.id)
+	}
+	b.mu.Unlock()
+	c.close()
+
+	if will := c.takeWill(); will != nil {
+		b.publish(will)
+	}
+}
+
+// readLoop processa os pacotes do cliente até a desconexão. Retorna nil
+// quando o cliente envia DISCONNECT
+func (b *Broker) readLoop(c *client) error {
+	for {
+		if c.keepalive > 0 {
+			// A especificação tolera uma vez e meia o keepalive sem pacotes
+			c.conn.SetReadDeadline(time.Now().Add(c.keepalive * 3 / 2))
+		} else {
+			c.conn.SetReadDeadline(time.Time{})
+		}
+		packet, err := readPacket(c.conn, b.maxPacketSize)
+		if err != nil {
+			return err
+		}
+
+		switch p := packet.(type) {
+		case *packets.PublishPacket:
+			if err := b.handlePublish(c, p); err != nil {
+				return err
+			}
+		case *packets.PubrelPacket:
+			delete(c.inflight, p.MessageID)
+			pubcomp := packets.NewControlPacket(packets.Pubcomp).(*packets.PubcompPacket)
+			pubcomp.MessageID = p.MessageID
+			c.send(pubcomp)
+		case *packets.PubackPacket, *packets.PubrecPacket, *packets.PubcompPacket:
+			// Confirmações das entregas; sem sessão persistente não há retransmissão
+		case *packets.SubscribePacket:
+			b.handleSubscribe(c, p)
+		case *packets.UnsubscribePacket:
+			c.mu.Lock()
+			for _, filter := range p.Topics {
+				delete(c.subscriptions, filter)
+			}
+			c.mu.Unlock()
+			unsuback := packets.NewControlPacket(packets.Unsuback).(*packets.UnsubackPacket)
+			unsuback.MessageID = p.MessageID
+			c.send(unsuback)
+		case *packets.PingreqPacket:
+			c.send(packets.NewControlPacket(packets.Pingresp))
+		case *packets.DisconnectPacket:
+			c.setWill(nil)
+			return nil
+		default:
+			return fmt.Errorf("pacote inesperado: %s", packet.String())
+		}
+	}
+}
+
+// handlePublish confirma e distribui uma publicação do cliente
+func (b *Broker) handlePublish(c *client, p *packets.PublishPacket) error {
+	if !validTopic(p.TopicName) {
+		return fmt.Errorf("tópico inválido para publicação: %q", p.TopicName)
+	}
+	switch p.Qos {
+	case 1:
+		puback := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+		puback.MessageID = p.MessageID
+		c.send(puback)
+	case 2:
+		pubrec := packets.NewControlPacket(packets.Pubrec).(*packets.PubrecPacket)
+		pubrec.MessageID = p.MessageID
+		c.send(pubrec)
+		// Retransmissão de uma mensagem ainda não liberada: entrega uma vez só
+		if c.inflight[p.MessageID] {
+			return nil
+		}
+		c.inflight[p.MessageID] = true
+	}
+	b.publish(p)
+	return nil
+}
+
+// handleSubscribe registra as assinaturas e envia as mensagens retidas
+// que correspondem a elas
+func (b *Broker) handleSubscribe(c *client, p *packets.SubscribePacket) {
+	suback := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+	suback.MessageID = p.MessageID
+	var accepted []string
+	c.mu.Lock()
+	for i, filter := range p.Topics {
+		if !validFilter(filter) {
+			suback.ReturnCodes = append(suback.ReturnCodes, 0x80)
+			continue
+		}
+		qos := min(p.Qoss[i], 1)
+		c.subscriptions[filter] = qos
+		suback.ReturnCodes = append(suback.ReturnCodes, qos)
+		accepted = append(accepted, filter)
+	}
+	c.mu.Unlock()
+	c.send(suback)
+
+	b.mu.Lock()
+	var retained []*packets.PublishPacket
+	for topic, msg := range b.retained {
+		for _, filter := range accepted {
+			if matchTopic(filter, topic) {
+				retained = append(retained, msg)
+				break
+			}
+		}
+	}
+	b.mu.Unlock()
+	for _, msg := range retained {
+		c.deliver(msg, true)
+	}
+}
+
+// publish guarda a mensagem retida e a entrega aos assinantes
+func (b *Broker) publish(p *packets.PublishPacket) {
+	b.mu.Lock()
+	if p.Retain {
+		if len(p.Payload) == 0 {
+			delete(b.retained, p.TopicName)
+		} else {
+			msg := p.Copy()
+			msg.Qos = p.Qos
+			b.retained[p.TopicName] = msg
+		}
+	}
+	clients := make([]*client, 0, len(b.clients))
+	for _, c := range b.clients {
+		clients = append(clients, c)
+	}
+	b.mu.Unlock()
+
+	for _, c := range clients {
+		c.deliver(p, false)
+	}
+}
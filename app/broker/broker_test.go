@@ -0,0 +1,332 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"projeto/app/ingest"
+	"projeto/app/mqtt"
+	"projeto/app/qc"
+	"strings"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+// startBroker inicia um broker em uma porta livre da interface de loopback
+func startBroker(t *testing.T, cfg Config) *Broker {
+	t.Helper()
+	cfg.Listeners = []string{"127.0.0.1:0"}
+	b, err := Start(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+// connect abre um cliente paho no primeiro listener do broker
+func connect(t *testing.T, b *Broker, id, user, password string) (paho.Client, error) {
+	t.Helper()
+	opts := paho.NewClientOptions()
+	opts.AddBroker("tcp://" + b.Addrs()[0].String())
+	opts.SetClientID(id)
+	opts.SetUsername(user)
+	opts.SetPassword(password)
+	opts.SetAutoReconnect(false)
+	opts.SetConnectTimeout(2 * time.Second)
+	c := paho.NewClient(opts)
+	token := c.Connect()
+	if !token.WaitTimeout(3 * time.Second) {
+		return nil, context.DeadlineExceeded
+	}
+	if token.Error() != nil {
+		return nil, token.Error()
+	}
+	t.Cleanup(func() { c.Disconnect(250) })
+	return c, nil
+}
+
+// subscribe assina os filtros e devolve o canal com as mensagens recebidas
+func subscribe(t *testing.T, c paho.Client, filters ...string) <-chan paho.Message {
+	t.Helper()
+	received := make(chan paho.Message, 32)
+	topics := map[string]byte{}
+	for _, f := range filters {
+		topics[f] = 1
+	}
+	token := c.SubscribeMultiple(topics, func(_ paho.Client, msg paho.Message) { received <- msg })
+	if !token.WaitTimeout(2*time.Second) || token.Error() != nil {
+		t.Fatalf("falha na inscrição em %v: %v", filters, token.Error())
+	}
+	return received
+}
+
+func publish(t *testing.T, c paho.Client, topic string, retained bool, payload string) {
+	t.Helper()
+	token := c.Publish(topic, 1, retained, payload)
+	if !token.WaitTimeout(2*time.Second) || token.Error() != nil {
+		t.Fatalf("falha ao publicar em %s: %v", topic, token.Error())
+	}
+}
+
+// waitFor repete a condição até ela valer ou o prazo acabar
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("tempo esgotado esperando %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRejectsBadCredentials(t *testing.T) {
+	b := startBroker(t, Config{Users: map[string]string{"estacao": "segredo"}})
+
+	if _, err := connect(t, b, "anonimo", "", ""); err == nil {
+		t.Error("conexão anônima aceita com usuários configurados")
+	}
+	if _, err := connect(t, b, "errado", "estacao", "chute"); err == nil {
+		t.Error("conexão com senha errada aceita")
+	}
+	if _, err := connect(t, b, "reservado", LocalUser, "chute"); err == nil {
+		t.Error("usuário interno aceito com senha errada")
+	}
+	if _, err := connect(t, b, "estacao", "estacao", "segredo"); err != nil {
+		t.Fatalf("conexão válida recusada: %v", err)
+	}
+	waitFor(t, "um cliente conectado", func() bool { return b.Stats().Clients == 1 })
+}
+
+func TestRetainedDeliveredOnSubscribe(t *testing.T) {
+	b := startBroker(t, Config{})
+	publisher, err := connect(t, b, "estacao", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	publish(t, publisher, "konda/estado", true, "online")
+	waitFor(t, "a mensagem retida", func() bool { return b.Stats().Retained == 1 })
+
+	subscriber, err := connect(t, b, "painel", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-subscribe(t, subscriber, "konda/#"):
+		if msg.Topic() != "konda/estado" || string(msg.Payload()) != "online" || !msg.Retained() {
+			t.Errorf("mensagem retida = %s %q retained=%v", msg.Topic(), msg.Payload(), msg.Retained())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("mensagem retida não entregue na inscrição")
+	}
+
+	// Payload vazio apaga a mensagem retida
+	publish(t, publisher, "konda/estado", true, "")
+	waitFor(t, "a remoção da mensagem retida", func() bool { return b.Stats().Retained == 0 })
+}
+
+func TestWildcardMatching(t *testing.T) {
+	b := startBroker(t, Config{})
+	publisher, err := connect(t, b, "estacao", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscriptions := map[string][]string{
+		"tudo":     {"#"},
+		"nivel":    {"+/status"},
+		"estacoes": {"estacoes/+/leituras"},
+		"arvore":   {"estacoes/#"},
+		"sistema":  {"$SYS/#"},
+	}
+	received := map[string]<-chan paho.Message{}
+	for id, filters := range subscriptions {
+		c, err := connect(t, b, id, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		received[id] = subscribe(t, c, filters...)
+	}
+
+	for _, topic := range []string{"konda/status", "estacoes/konda/leituras", "estacoes", "$SYS/status"} {
+		publish(t, publisher, topic, false, topic)
+	}
+
+	want := map[string][]string{
+		"tudo":     {"konda/status", "estacoes/konda/leituras", "estacoes"},
+		"nivel":    {"konda/status"},
+		"estacoes": {"estacoes/konda/leituras"},
+		"arvore":   {"estacoes/konda/leituras", "estacoes"},
+		"sistema":  {"$SYS/status"},
+	}
+	for id, topics := range want {
+		got := map[string]bool{}
+		timeout := time.After(2 * time.Second)
+		for len(got) < len(topics) {
+			select {
+			case msg := <-received[id]:
+				got[msg.Topic()] = true
+			case <-timeout:
+				t.Fatalf("%s recebeu %v, esperado %v", id, got, topics)
+			}
+		}
+		select {
+		case msg := <-received[id]:
+			t.Errorf("%s recebeu %s, que não corresponde à assinatura", id, msg.Topic())
+		case <-time.After(100 * time.Millisecond):
+		}
+		for _, topic := range topics {
+			if !got[topic] {
+				t.Errorf("%s não recebeu %s", id, topic)
+			}
+		}
+	}
+}
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"konda", "konda", true},
+		{"konda", "konda/x", false},
+		{"+", "konda", true},
+		{"+", "konda/x", false},
+		{"konda/+", "konda/", true},
+		{"konda/#", "konda", true},
+		{"konda/#", "konda/a/b", true},
+		{"#", "konda/a", true},
+		{"#", "$SYS/a", false},
+		{"+/a", "$SYS/a", false},
+		{"$SYS/#", "$SYS/a", true},
+		{"$SYS/+", "$SYS/a", true},
+	}
+	for _, tt := range tests {
+		if got := matchTopic(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("matchTopic(%q, %q) = %v, esperado %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestRejectsOversizedPackets(t *testing.T) {
+	b := startBroker(t, Config{MaxPacketSize: 1024})
+
+	// Antes do CONNECT: cabeçalho declarando 200 MiB é recusado sem ler o corpo
+	conn, err := net.Dial("tcp", b.Addrs()[0].String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte{0x10, 0x80, 0x80, 0x80, 0x64})
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if n, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Fatalf("conexão mantida após cabeçalho gigante (%d bytes lidos)", n)
+	} else if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		t.Fatal("broker esperou o corpo de um pacote acima do limite")
+	}
+
+	// Após o CONNECT: publicação acima de MaxPacketSize derruba o cliente
+	c, err := connect(t, b, "grande", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "o cliente conectado", func() bool { return b.Stats().Clients == 1 })
+	c.Publish("konda", 0, false, strings.Repeat("x", 2048))
+	waitFor(t, "a desconexão do cliente", func() bool { return b.Stats().Clients == 0 })
+}
+
+func TestReadPacketLimits(t *testing.T) {
+	if _, err := readPacket(bytes.NewReader([]byte{0x30, 0xff, 0xff, 0xff, 0xff, 0x01}), 1<<30); err != errMalformedLength {
+		t.Errorf("comprimento com 5 bytes: err = %v", err)
+	}
+	pingreq := []byte{0xc0, 0x00}
+	if _, err := readPacket(bytes.NewReader(pingreq), 0); err != nil {
+		t.Errorf("PINGREQ recusado: %v", err)
+	}
+}
+
+func TestLocalURL(t *testing.T) {
+	b := startBroker(t, Config{Users: map[string]string{"estacao": "segredo"}})
+	u, err := url.Parse(b.LocalURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != b.Addrs()[0].String() {
+		t.Errorf("LocalURL usa %s, listener em %s", u.Host, b.Addrs()[0])
+	}
+	password, _ := u.User.Password()
+	if _, err := connect(t, b, "interno", u.User.Username(), password); err != nil {
+		t.Errorf("usuário interno recusado: %v", err)
+	}
+
+	wildcard, err := Start(Config{Listeners: []string{":0"}, Users: map[string]string{"estacao": "segredo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wildcard.Close()
+	u, _ = url.Parse(wildcard.LocalURL())
+	if u.Hostname() != "127.0.0.1" {
+		t.Errorf("listener em todas as interfaces acessado por %s", u.Hostname())
+	}
+}
+
+func TestRefusesAnonymousOutsideLoopback(t *testing.T) {
+	if b, err := Start(Config{Listeners: []string{":0"}}); err == nil {
+		b.Close()
+		t.Fatal("listener em todas as interfaces aceito sem usuários")
+	}
+	b, err := Start(Config{Listeners: []string{":0"}, AllowAnonymous: true})
+	if err != nil {
+		t.Fatalf("anônimos permitidos explicitamente: %v", err)
+	}
+	b.Close()
+}
+
+func TestPublishReachesIngestion(t *testing.T) {
+	// As leituras decodificadas vão para um destino falso em vez do MySQL
+	t.Setenv("MQTT_RECORD_FILE", filepath.Join(t.TempDir(), "gravacao.ndjson"))
+	stored := make(chan ingest.Reading, 32)
+	mqtt.SetStore(func(readings []ingest.Reading) {
+		for _, r := range readings {
+			stored <- r
+		}
+	})
+	defer mqtt.SetStore(nil)
+
+	b := startBroker(t, Config{Users: map[string]string{"estacao": "segredo"}})
+	mqtt.SetupMQTT(b.LocalURL())
+	waitFor(t, "o cliente principal conectado", func() bool { return mqtt.Status().Connected })
+
+	station, err := connect(t, b, "estacao", "estacao", "segredo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := `[{"bn":"teste-broker","bt":1700000000,"n":"emw_temperature","v":21.5}]`
+	var reading ingest.Reading
+	deadline := time.After(5 * time.Second)
+	for received := false; !received; {
+		// A inscrição do cliente principal é assíncrona; republica até chegar
+		publish(t, station, mqtt.Topic, false, payload)
+		select {
+		case reading = <-stored:
+			received = true
+		case <-time.After(200 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("publicação em " + mqtt.Topic + " não chegou à ingestão")
+		}
+	}
+	if reading.Station != "teste-broker" || reading.Timestamp != 1700000000 || reading.Values[qc.Temperature] != 21.5 {
+		t.Errorf("leitura decodificada = %+v", reading)
+	}
+
+	content, err := os.ReadFile(os.Getenv("MQTT_RECORD_FILE"))
+	if err != nil || !strings.Contains(string(content), "teste-broker") {
+		t.Errorf("mensagem não gravada antes da ingestão: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package broker
+
+import (
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// client é uma conexão MQTT aceita
+type client struct {
+	conn      net.Conn
+	id        string
+	keepalive time.Duration
+
+	mu            sync.Mutex
+	subscriptions map[string]byte // filtro -> QoS concedido
+	will          *packets.PublishPacket
+	nextID        uint16
+
+	// inflight são as publicações QoS 2 recebidas e ainda não liberadas
+	// (PUBREL); usado só pela goroutine de leitura
+	inflight map[uint16]bool
+
+	out       chan packets.ControlPacket
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newClient(conn net.Conn, cp *packets.ConnectPacket) *client {
+	c := &client{
+		conn:          conn,
+		id:            cp.ClientIdentifier,
+		keepalive:     time.Duration(cp.Keepalive) * time.Second,
+		subscriptions: map[string]byte{},
+		inflight:      map[uint16]bool{},
+		out:           make(chan packets.ControlPacket, outboxSize),
+		done:          make(chan struct{}),
+	}
+	if cp.WillFlag {
+		will := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+		will.TopicName = cp.WillTopic
+		will.Payload = cp.WillMessage
+		will.Qos = cp.WillQos
+		will.Retain = cp.WillRetain
+		c.will = will
+	}
+	return c
+}
+
+// send enfileira um pacote para envio, descartando-o se o cliente não
+// estiver consumindo a fila
+func (c *client) send(p packets.ControlPacket) {
+	select {
+	case <-c.done:
+	case c.out <- p:
+	default:
+		slog.Warn("Fila de envio MQTT cheia, pacote descartado", "client", c.id, "packet", p.String())
+	}
+}
+
+// writeLoop escreve os pacotes enfileirados até a conexão ser fechada
+func (c *client) writeLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case p := <-c.out:
+			if err := p.Write(c.conn); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// close encerra a conexão; a goroutine de leitura termina com erro
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// setWill troca a mensagem de última vontade
+func (c *client) setWill(will *packets.PublishPacket) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.will = will
+}
+
+// takeWill retorna e descarta a mensagem de última vontade
+func (c *client) takeWill() *packets.PublishPacket {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	will := c.will
+	c.will = nil
+	return will
+}
+
+// deliver envia a mensagem se alguma assinatura corresponder ao tópico, com
+// o maior QoS concedido entre elas limitado ao da publicação. retained marca
+// as mensagens retidas enviadas na inscrição
+func (c *client) deliver(p *packets.PublishPacket, retained bool) {
+	c.mu.Lock()
+	granted, matched := byte(0), false
+	for filter, qos := range c.subscriptions {
+		if matchTopic(filter, p.TopicName) {
+			matched = true
+			granted = max(granted, qos)
+		}
+	}
+	if !matched {
+		c.mu.Unlock()
+		return
+	}
+	msg := p.Copy()
+	msg.Qos = min(p.Qos, granted)
+	msg.Retain = retained
+	if msg.Qos > 0 {
+		c.nextID++
+		if c.nextID == 0 {
+			c.nextID = 1
+		}
+		msg.MessageID = c.nextID
+	}
+	c.mu.Unlock()
+	c.send(msg)
+}
+
+// validTopic indica se o tópico pode receber publicações: não vazio e sem curingas
+func validTopic(topic string) bool {
+	return topic != "" && !strings.ContainsAny(topic, "+#\x00")
+}
+
+// validFilter confere os curingas de um filtro de assinatura: + ocupa um
+// nível inteiro e # só aparece como último nível
+func validFilter(filter string) bool {
+	if filter == "" || strings.Contains(filter, "\x00") {
+		return false
+	}
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if strings.Contains(level, "#") && (level != "#" || i != len(levels)-1) {
+			return false
+		}
+		if strings.Contains(level, "+") && level != "+" {
+			return false
+		}
+	}
+	return true
+}
+
+// matchTopic indica se o tópico corresponde ao filtro. Tópicos iniciados
+// por $ não correspondem a filtros que começam com curinga
+func matchTopic(filter, topic string) bool {
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) {
+			return false
+		}
+		if level != "+" && level != topicLevels[i] {
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
@@ -0,0 +1,51 @@
+package broker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// errMalformedLength indica um comprimento restante com mais de 4 bytes
+var errMalformedLength = errors.New("comprimento restante malformado")
+
+// readPacket lê um pacote recusando os que declaram mais de limit bytes
+// após o cabeçalho fixo. packets.ReadPacket aloca o comprimento declarado
+// (até 256 MiB) antes de ler o corpo, então o cabeçalho é lido aqui e só um
+// pacote dentro do limite chega a ele
+func readPacket(r io.Reader, limit int) (packets.ControlPacket, error) {
+	header := make([]byte, 1, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return nil, errMalformedLength
+		}
+		var b [1]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		header = append(header, b[0])
+		length += int(b[0]&0x7f) * multiplier
+		multiplier *= 128
+		if b[0]&0x80 == 0 {
+			break
+		}
+	}
+	if length > limit {
+		return nil, fmt.Errorf("pacote de %d bytes excede o limite de %d", length, limit)
+	}
+
+	packet := make([]byte, len(header)+length)
+	copy(packet, header)
+	if _, err := io.ReadFull(r, packet[len(header):]); err != nil {
+		return nil, err
+	}
+	return packets.ReadPacket(bytes.NewReader(packet))
+}
@@ -0,0 +1,196 @@
+package calibration
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"projeto/app/qc"
+	"sync"
+	"time"
+)
+
+// Calibration corrige uma métrica de uma estação a partir de EffectiveFrom
+// (e até EffectiveTo, se definido). O valor corrigido é
+// Gain * p(bruto) + Offset, onde p é o polinômio de Coefficients
+// (c0 + c1*x + c2*x² ...) ou a identidade quando não há coeficientes
+type Calibration struct {
+	ID            int64     `json:"id"`
+	Station       string    `json:"station"`
+	Metric        string    `json:"metric"`
+	Offset        float64   `json:"offset"`
+	Gain          float64   `json:"gain"`
+	Coefficients  []float64 `json:"coefficients,omitempty"`
+	EffectiveFrom int64     `json:"effective_from"`
+	EffectiveTo   *int64    `json:"effective_to,omitempty"`
+	Note          string    `json:"note,omitempty"`
+}
+
+// Correct aplica a calibração a um valor bruto
+func (c Calibration) Correct(raw float64) float64 {
+	value := raw
+	if len(c.Coefficients) > 0 {
+		// Avaliação pelo método de Horner
+		value = 0
+		for i := len(c.Coefficients) - 1; i >= 0; i-- {
+			value = value*raw + c.Coefficients[i]
+		}
+	}
+	return c.Gain*value + c.Offset
+}
+
+// Covers indica se a calibração vale para a estação no instante timestamp
+func (c Calibration) Covers(station string, timestamp int64) bool {
+	return c.Station == station && timestamp >= c.EffectiveFrom &&
+		(c.EffectiveTo == nil || timestamp < *c.EffectiveTo)
+}
+
+// Validate verifica a métrica e o período da calibração
+func (c Calibration) Validate() error {
+	known := false
+	for _, m := range qc.Metrics {
+		if m == c.Metric {
+			known = true
+		}
+	}
+	switch {
+	case c.Station == "":
+		return fmt.Errorf("estação não informada")
+	case !known:
+		return fmt.Errorf("métrica desconhecida: %s", c.Metric)
+	case c.Gain == 0:
+		return fmt.Errorf("ganho não pode ser zero")
+	case c.EffectiveTo != nil && *c.EffectiveTo <= c.EffectiveFrom:
+		return fmt.Errorf("fim da vigência deve ser posterior ao início")
+	}
+	return nil
+}
+
+// Set é um conjunto de calibrações
+type Set []Calibration
+
+// Find retorna a calibração vigente da métrica, preferindo a de início mais recente
+func (s Set) Find(station, metric string, timestamp int64) *Calibration {
+	var found *Calibration
+	for i := range s {
+		c := &s[i]
+		if c.Metric == metric && c.Covers(station, timestamp) &&
+			(found == nil || c.EffectiveFrom > found.EffectiveFrom) {
+			found = c
+		}
+	}
+	return found
+}
+
+// Apply retorna uma cópia dos valores com as calibrações vigentes aplicadas
+func (s Set) Apply(station string, timestamp int64, values map[string]float64) map[string]float64 {
+	corrected := make(map[string]float64, len(values))
+	for metric, value := range values {
+		if c := s.Find(station, metric, timestamp); c != nil {
+			value = c.Correct(value)
+		}
+		corrected[metric] = value
+	}
+	return corrected
+}
+
+// cacheTTL é o tempo em que as calibrações lidas do banco são reaproveitadas
+const cacheTTL = time.Minute
+
+var (
+	cacheMu     sync.Mutex
+	cached      Set
+	cachedAt    time.Time
+	cacheLoaded bool
+)
+
+// Current retorna as calibrações, relendo o banco no máximo uma vez por minuto
+func Current(db *sql.DB) Set {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if cacheLoaded && time.Since(cachedAt) < cacheTTL {
+		return cached
+	}
+	set, err := Load(db)
+	if err != nil {
+		slog.Error("Erro ao carregar calibrações", "err", err)
+		return cached
+	}
+	cached, cachedAt, cacheLoaded = set, time.Now(), true
+	return cached
+}
+
+// Invalidate força a releitura das calibrações na próxima leitura
+func Invalidate() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheLoaded = false
+}
+
+// Apply aplica as calibrações vigentes aos valores de uma leitura
+func Apply(db *sql.DB, station string, timestamp int64, values map[string]float64) map[string]float64 {
+	return Current(db).Apply(station, timestamp, values)
+}
+
+// Load lê todas as calibrações do banco
+func Load(db *sql.DB) (Set, error) {
+	rows, err := db.Query(`
+        SELECT id, station, metric, offset_value, gain, coefficients,
+               effective_from, effective_to, note
+        FROM calibrations
+        ORDER BY station, metric, effective_from
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	set := Set{}
+	for rows.Next() {
+		var c Calibration
+		var coefficients sql.NullString
+		var note sql.NullString
+		if err := rows.Scan(&c.ID, &c.Station, &c.Metric, &c.Offset, &c.Gain, &coefficients,
+			&c.EffectiveFrom, &c.EffectiveTo, &note); err != nil {
+			return nil, err
+		}
+		if coefficients.Valid && coefficients.String != "" {
+			if err := json.Unmarshal([]byte(coefficients.String), &c.Coefficients); err != nil {
+				return nil, fmt.Errorf("coeficientes inválidos na calibração %d: %w", c.ID, err)
+			}
+		}
+		c.Note = note.String
+		set = append(set, c)
+	}
+	return set, rows.Err()
+}
+
+// Add grava uma nova calibração
+func Add(db *sql.DB, c Calibration) (int64, error) {
+	if err := c.Validate(); err != nil {
+		return 0, err
+	}
+
+	var coefficients interface{}
+	if len(c.Coefficients) > 0 {
+		b, err := json.Marshal(c.Coefficients)
+		if err != nil {
+			return 0, err
+		}
+		coefficients = string(b)
+	}
+
+	result, err := db.Exec(`
+        INSERT INTO calibrations (
+            station, metric, offset_value, gain, coefficients,
+            effective_from, effective_to, note, created_at
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `, c.Station, c.Metric, c.Offset, c.Gain, coefficients,
+		c.EffectiveFrom, c.EffectiveTo, c.Note, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	Invalidate()
+	return result.LastInsertId()
+}
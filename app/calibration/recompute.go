@@ -0,0 +1,130 @@
+package calibration
+
+import (
+	"database/sql"
+	"fmt"
+	"projeto/app/qc"
+	"projeto/app/summary"
+	"strings"
+	"time"
+)
+
+// columns são as colunas de medidas comuns a sensor_data e sensor_data_raw
+var columns = strings.Join(qc.Metrics, ", ")
+
+// SaveRaw grava os valores brutos de uma leitura em sensor_data_raw.
+// Métricas ausentes de values são gravadas como NULL
+func SaveRaw(db *sql.DB, station string, timestamp int64, values map[string]float64) error {
+	args := []interface{}{station, timestamp}
+	updates := make([]string, len(qc.Metrics))
+	for i, metric := range qc.Metrics {
+		if value, ok := values[metric]; ok {
+			args = append(args, value)
+		} else {
+			args = append(args, nil)
+		}
+		updates[i] = fmt.Sprintf("%s=VALUES(%s)", metric, metric)
+	}
+	_, err := db.Exec(fmt.Sprintf(`
+        INSERT INTO sensor_data_raw (station, timestamp, %s)
+        VALUES (?, ?%s)
+        ON DUPLICATE KEY UPDATE %s
+    `, columns, strings.Repeat(", ?", len(qc.Metrics)), strings.Join(updates, ", ")), args...)
+	return err
+}
+
+// Recompute recalcula os valores calibrados e os indicadores de qualidade
+// de sensor_data para a estação no período, a partir dos valores brutos.
+// Leituras anteriores à calibração na ingestão têm o valor atual copiado
+// como bruto antes do recálculo. Retorna a quantidade de leituras atualizadas
+func Recompute(db *sql.DB, station string, from, to int64) (int, error) {
+	_, err := db.Exec(fmt.Sprintf(`
+        INSERT IGNORE INTO sensor_data_raw (station, timestamp, %s)
+        SELECT station, timestamp, %s FROM sensor_data
+        WHERE station = ? AND timestamp BETWEEN ? AND ?
+    `, columns, columns), station, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao preservar valores brutos: %w", err)
+	}
+
+	set, err := Load(db)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao carregar calibrações: %w", err)
+	}
+
+	rows, err := db.Query(fmt.Sprintf(`
+        SELECT timestamp, %s FROM sensor_data_raw
+        WHERE station = ? AND timestamp BETWEEN ? AND ?
+        ORDER BY timestamp
+    `, columns), station, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao buscar valores brutos: %w", err)
+	}
+
+	type reading struct {
+		timestamp int64
+		values    map[string]float64
+		null      map[string]bool
+	}
+	var readings []reading
+	for rows.Next() {
+		var timestamp int64
+		raw := make([]sql.NullFloat64, len(qc.Metrics))
+		dest := []interface{}{&timestamp}
+		for i := range raw {
+			dest = append(dest, &raw[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		r := reading{timestamp: timestamp, values: map[string]float64{}, null: map[string]bool{}}
+		for i, metric := range qc.Metrics {
+			if raw[i].Valid {
+				r.values[metric] = raw[i].Float64
+			} else {
+				r.null[metric] = true
+			}
+		}
+		readings = append(readings, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// O controle de qualidade é refeito em ordem com um histórico próprio
+	checker := qc.NewChecker(qc.DefaultLimits)
+	assignments := make([]string, len(qc.Metrics))
+	for i, metric := range qc.Metrics {
+		assignments[i] = metric + " = ?"
+	}
+	update := fmt.Sprintf("UPDATE sensor_data SET %s, qc_flags = ? WHERE station = ? AND timestamp = ?",
+		strings.Join(assignments, ", "))
+
+	for _, r := range readings {
+		corrected := set.Apply(station, r.timestamp, r.values)
+		flags := checker.Check(station, r.timestamp, corrected)
+
+		args := make([]interface{}, 0, len(qc.Metrics)+3)
+		for _, metric := range qc.Metrics {
+			if r.null[metric] {
+				args = append(args, nil)
+			} else {
+				args = append(args, corrected[metric])
+			}
+		}
+		args = append(args, flags, station, r.timestamp)
+		if _, err := db.Exec(update, args...); err != nil {
+			return 0, fmt.Errorf("erro ao atualizar leitura %d: %w", r.timestamp, err)
+		}
+	}
+
+	if len(readings) > 0 {
+		if _, err := summary.Backfill(db, station, time.Unix(from, 0), time.Unix(to, 0)); err != nil {
+			return len(readings), fmt.Errorf("erro ao atualizar resumos diários: %w", err)
+		}
+	}
+	return len(readings), nil
+}
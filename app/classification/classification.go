@@ -0,0 +1,282 @@
+package classification
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Grandezas classificadas. Os nomes coincidem com o prefixo das mensagens
+// "status.<grandeza>.<código>" do catálogo de tradução
+const (
+	Temperature = "temperature"
+	Humidity    = "humidity"
+	Rain        = "rain" // variação do pluviômetro entre as duas últimas leituras (mm)
+	UV          = "uv"
+	Wind        = "wind" // velocidade média em km/h
+)
+
+// Kinds são as grandezas aceitas nas tabelas
+var Kinds = []string{Temperature, Humidity, Rain, UV, Wind}
+
+// Band é uma faixa da tabela. O valor pertence à faixa se for menor que
+// Below ou menor ou igual a Max; sem nenhum dos dois a faixa é aberta e
+// deve ser a última. Labels permite rótulos próprios por idioma para códigos
+// que não existem no catálogo
+type Band struct {
+	Code   string            `json:"code"`
+	Below  *float64          `json:"below,omitempty"`
+	Max    *float64          `json:"max,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// contains indica se o valor pertence à faixa
+func (b Band) contains(value float64) bool {
+	switch {
+	case b.Below != nil:
+		return value < *b.Below
+	case b.Max != nil:
+		return value <= *b.Max
+	default:
+		return true
+	}
+}
+
+// limit retorna o limite superior da faixa (ou nil se aberta)
+func (b Band) limit() *float64 {
+	if b.Below != nil {
+		return b.Below
+	}
+	return b.Max
+}
+
+// Table é a lista de faixas de uma grandeza, em ordem crescente
+type Table []Band
+
+// Classify retorna o código da primeira faixa que contém o valor
+func (t Table) Classify(value float64) string {
+	for _, b := range t {
+		if b.contains(value) {
+			return b.Code
+		}
+	}
+	return ""
+}
+
+// Validate confere se as faixas estão em ordem crescente e se a última é aberta
+func (t Table) Validate() error {
+	if len(t) == 0 {
+		return fmt.Errorf("tabela vazia")
+	}
+	for i, b := range t {
+		if b.Code == "" {
+			return fmt.Errorf("faixa %d sem código", i)
+		}
+		if b.Below != nil && b.Max != nil {
+			return fmt.Errorf("faixa %s: use below ou max, não ambos", b.Code)
+		}
+		last := i == len(t)-1
+		if b.limit() == nil && !last {
+			return fmt.Errorf("faixa %s: somente a última faixa pode ser aberta", b.Code)
+		}
+		if b.limit() != nil && last {
+			return fmt.Errorf("faixa %s: a última faixa deve ser aberta", b.Code)
+		}
+		if i > 0 && b.limit() != nil && *b.limit() < *t[i-1].limit() {
+			return fmt.Errorf("faixa %s: limites fora de ordem", b.Code)
+		}
+	}
+	return nil
+}
+
+// Tables associa cada grandeza à sua tabela
+type Tables map[string]Table
+
+// Config são as tabelas padrão, os perfis de clima (ex.: "litoral", "serra")
+// e as substituições por estação. StationClimates associa cada estação a um
+// perfil, como {"konda": "litoral"}; o classificacao.json distribuído traz os
+// perfis de exemplo sem nenhuma estação associada, mantendo as faixas padrão.
+// Cada nível substitui o anterior apenas nas grandezas que define
+type Config struct {
+	Default         Tables            `json:"default"`
+	Climates        map[string]Tables `json:"climates,omitempty"`
+	StationClimates map[string]string `json:"station_climates,omitempty"`
+	Stations        map[string]Tables `json:"stations,omitempty"`
+}
+
+func limit(v float64) *float64 {
+	return &v
+}
+
+// DefaultTables reproduzem as faixas originais do sistema
+var DefaultTables = Tables{
+	Temperature: {
+		{Code: "very_cold", Below: limit(10)},
+		{Code: "cold", Below: limit(20)},
+		{Code: "pleasant", Below: limit(25)},
+		{Code: "hot", Below: limit(30)},
+		{Code: "very_hot"},
+	},
+	Humidity: {
+		{Code: "dry", Below: limit(30)},
+		{Code: "comfortable", Below: limit(60)},
+		{Code: "humid"},
+	},
+	Rain: {
+		{Code: "none", Max: limit(0)},
+		{Code: "drizzle", Below: limit(0.0006)},
+		{Code: "raining"},
+	},
+	UV: {
+		{Code: "low", Below: limit(3)},
+		{Code: "moderate", Below: limit(6)},
+		{Code: "high", Below: limit(8)},
+		{Code: "very_high", Below: limit(11)},
+		{Code: "extreme"},
+	},
+	Wind: {
+		{Code: "calm", Max: limit(0)},
+		{Code: "light_breeze", Below: limit(12)},
+		{Code: "fresh", Below: limit(20)},
+		{Code: "moderate", Below: limit(41)},
+		{Code: "strong", Below: limit(62)},
+		{Code: "very_strong", Below: limit(75)},
+		{Code: "severe_gale", Below: limit(103)},
+		{Code: "storm", Below: limit(120)},
+		{Code: "hurricane"},
+	},
+}
+
+// Table retorna a tabela da grandeza para a estação: a da estação, se houver,
+// senão a do seu perfil de clima, a padrão da configuração e, por fim, a embutida
+func (c Config) Table(station, kind string) Table {
+	if t, ok := c.Stations[station][kind]; ok {
+		return t
+	}
+	if t, ok := c.Climates[c.StationClimates[station]][kind]; ok {
+		return t
+	}
+	if t, ok := c.Default[kind]; ok {
+		return t
+	}
+	return DefaultTables[kind]
+}
+
+// Classify classifica o valor da grandeza para a estação
+func (c Config) Classify(station, kind string, value float64) string {
+	return c.Table(station, kind).Classify(value)
+}
+
+// Label procura um rótulo próprio para o código no idioma pedido, na tabela
+// da grandeza usada pela estação
+func (c Config) Label(station, kind, code, lang string) (string, bool) {
+	for _, b := range c.Table(station, kind) {
+		if b.Code == code {
+			label, ok := b.Labels[lang]
+			return label, ok
+		}
+	}
+	return "", false
+}
+
+// Validate confere todas as tabelas da configuração
+func (c Config) Validate() error {
+	check := func(scope string, tables Tables) error {
+		for kind, t := range tables {
+			if !validKind(kind) {
+				return fmt.Errorf("%s: grandeza desconhecida: %s", scope, kind)
+			}
+			if err := t.Validate(); err != nil {
+				return fmt.Errorf("%s/%s: %w", scope, kind, err)
+			}
+		}
+		return nil
+	}
+	if err := check("default", c.Default); err != nil {
+		return err
+	}
+	for climate, tables := range c.Climates {
+		if err := check(climate, tables); err != nil {
+			return err
+		}
+	}
+	for station, climate := range c.StationClimates {
+		if _, ok := c.Climates[climate]; !ok {
+			return fmt.Errorf("%s: perfil de clima desconhecido: %s", station, climate)
+		}
+	}
+	for station, tables := range c.Stations {
+		if err := check(station, tables); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse interpreta e valida uma configuração em JSON
+func Parse(content []byte) (Config, error) {
+	var c Config
+	if err := json.Unmarshal(content, &c); err != nil {
+		return Config{}, fmt.Errorf("configuração de classificação inválida: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+// Load lê a configuração de um arquivo
+func Load(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return Parse(content)
+}
+
+var (
+	mu      sync.RWMutex
+	current = Config{Default: DefaultTables}
+)
+
+// Setup carrega as tabelas de CLASSIFICATION_FILE (padrão classificacao.json).
+// Sem arquivo, valem as faixas embutidas
+func Setup() {
+	path := os.Getenv("CLASSIFICATION_FILE")
+	if path == "" {
+		path = "classificacao.json"
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		slog.Warn("Faixas de classificação não carregadas, usando as padrão", "file", path, "err", err)
+		return
+	}
+	Set(c)
+	slog.Info("Faixas de classificação carregadas", "file", path, "stations", len(c.Stations))
+}
+
+// Set troca a configuração em uso
+func Set(c Config) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = c
+}
+
+// Current retorna a configuração em uso
+func Current() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
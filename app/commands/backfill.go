@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"projeto/app/config"
+	"projeto/app/summary"
+	"projeto/app/utils"
+	"time"
+)
+
+// Backfill reconstrói a tabela daily_summary a partir de sensor_data.
+// Uso: backfill [-from AAAA-MM-DD] [-to AAAA-MM-DD] [-station id]
+func Backfill(args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	from := fs.String("from", "", "primeiro dia (padrão: leitura mais antiga)")
+	to := fs.String("to", "", "último dia (padrão: hoje)")
+	station := fs.String("station", "", "estação (padrão: todas)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("mysql", config.DatabaseDSN())
+	if err != nil {
+		return fmt.Errorf("erro ao conectar ao banco: %w", err)
+	}
+	defer db.Close()
+
+	end := time.Now().In(utils.Local)
+	if *to != "" {
+		if end, err = time.ParseInLocation("2006-01-02", *to, utils.Local); err != nil {
+			return fmt.Errorf("data final inválida: %s", *to)
+		}
+	}
+
+	var start time.Time
+	if *from != "" {
+		if start, err = time.ParseInLocation("2006-01-02", *from, utils.Local); err != nil {
+			return fmt.Errorf("data inicial inválida: %s", *from)
+		}
+	} else {
+		var oldest sql.NullInt64
+		if err := db.QueryRow("SELECT MIN(timestamp) FROM sensor_data").Scan(&oldest); err != nil {
+			return fmt.Errorf("erro ao buscar leitura mais antiga: %w", err)
+		}
+		if !oldest.Valid {
+			slog.Info("Nenhuma leitura encontrada")
+			return nil
+		}
+		start = time.Unix(oldest.Int64, 0)
+	}
+
+	days, err := summary.Backfill(db, *station, start, end)
+	if err != nil {
+		return err
+	}
+	slog.Info("Resumos diários reconstruídos", "days", days)
+	return nil
+}
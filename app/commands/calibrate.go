@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"projeto/app/calibration"
+	"projeto/app/config"
+	"projeto/app/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Calibrate gerencia as calibrações dos sensores.
+// Uso:
+//
+//	calibrate list
+//	calibrate add -station konda -metric temperature -offset -0.8 -from 2025-01-01 [-gain 1] [-poly c0,c1,c2] [-to AAAA-MM-DD] [-note texto] [-recompute]
+//	calibrate recompute -station konda -from AAAA-MM-DD [-to AAAA-MM-DD]
+func Calibrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("uso: calibrate list|add|recompute [opções]")
+	}
+
+	db, err := sql.Open("mysql", config.DatabaseDSN())
+	if err != nil {
+		return fmt.Errorf("erro ao conectar ao banco: %w", err)
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "list":
+		set, err := calibration.Load(db)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(set)
+	case "add":
+		return calibrateAdd(db, args[1:])
+	case "recompute":
+		return calibrateRecompute(db, args[1:])
+	default:
+		return fmt.Errorf("subcomando desconhecido: %s", args[0])
+	}
+}
+
+func calibrateAdd(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("calibrate add", flag.ContinueOnError)
+	station := fs.String("station", "konda", "estação")
+	metric := fs.String("metric", "", "métrica (coluna de sensor_data)")
+	offset := fs.Float64("offset", 0, "deslocamento somado ao valor")
+	gain := fs.Float64("gain", 1, "fator multiplicativo")
+	poly := fs.String("poly", "", "coeficientes do polinômio c0,c1,c2,...")
+	from := fs.String("from", "", "início da vigência (AAAA-MM-DD)")
+	to := fs.String("to", "", "fim da vigência, exclusivo (AAAA-MM-DD)")
+	note := fs.String("note", "", "observação")
+	recompute := fs.Bool("recompute", false, "recalcular as leituras do período")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c := calibration.Calibration{Station: *station, Metric: *metric, Offset: *offset, Gain: *gain, Note: *note}
+	start, err := parseDate(*from)
+	if err != nil {
+		return err
+	}
+	c.EffectiveFrom = start.Unix()
+	if *to != "" {
+		end, err := parseDate(*to)
+		if err != nil {
+			return err
+		}
+		ts := end.Unix()
+		c.EffectiveTo = &ts
+	}
+	if *poly != "" {
+		for _, field := range strings.Split(*poly, ",") {
+			coefficient, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return fmt.Errorf("coeficiente inválido: %s", field)
+			}
+			c.Coefficients = append(c.Coefficients, coefficient)
+		}
+	}
+
+	id, err := calibration.Add(db, c)
+	if err != nil {
+		return err
+	}
+	slog.Info("Calibração gravada", "id", id, "station", c.Station, "metric", c.Metric)
+
+	if !*recompute {
+		return nil
+	}
+	end := time.Now().Unix()
+	if c.EffectiveTo != nil {
+		end = *c.EffectiveTo - 1
+	}
+	return runRecompute(db, c.Station, c.EffectiveFrom, end)
+}
+
+func calibrateRecompute(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("calibrate recompute", flag.ContinueOnError)
+	station := fs.String("station", "konda", "estação")
+	from := fs.String("from", "", "primeiro dia (AAAA-MM-DD)")
+	to := fs.String("to", "", "último dia, inclusivo (padrão: hoje)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	start, err := parseDate(*from)
+	if err != nil {
+		return err
+	}
+	end := time.Now().Unix()
+	if *to != "" {
+		day, err := parseDate(*to)
+		if err != nil {
+			return err
+		}
+		end = day.AddDate(0, 0, 1).Unix() - 1
+	}
+	return runRecompute(db, *station, start.Unix(), end)
+}
+
+func runRecompute(db *sql.DB, station string, from, to int64) error {
+	count, err := calibration.Recompute(db, station, from, to)
+	if err != nil {
+		return err
+	}
+	slog.Info("Leituras recalculadas", "station", station, "readings", count)
+	return nil
+}
+
+// parseDate interpreta uma data AAAA-MM-DD no fuso local
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("data não informada")
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, utils.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("data inválida: %s", value)
+	}
+	return date, nil
+}
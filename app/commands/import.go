@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"projeto/app/config"
+	"projeto/app/ingest"
+)
+
+// Import grava arquivos históricos CSV ou SenML pelo pipeline de ingestão.
+// Uso: import [-dry-run] [-format auto|csv|senml] [-station ID] arquivo...
+func Import(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "valida e mostra o relatório sem gravar")
+	format := fs.String("format", ingest.FormatAuto, "formato dos arquivos: auto, csv ou senml")
+	station := fs.String("station", "", "estação das leituras sem estação (padrão "+ingest.DefaultStation+")")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("informe ao menos um arquivo")
+	}
+
+	db, err := sql.Open("mysql", config.DatabaseDSN())
+	if err != nil {
+		return fmt.Errorf("erro ao conectar ao banco: %w", err)
+	}
+	defer db.Close()
+
+	opts := ingest.ImportOptions{Format: *format, Station: *station, DryRun: *dryRun}
+	for _, path := range fs.Args() {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		report, err := ingest.Import(db, file, opts)
+		file.Close()
+		fmt.Printf("== %s\n%s", path, ingest.FormatReport(report))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
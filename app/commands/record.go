@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"projeto/app/alerts"
+	"projeto/app/ingest"
+	"projeto/app/mqtt"
+	"time"
+)
+
+// Record grava as mensagens MQTT recebidas em um arquivo NDJSON, com tópico
+// e instante de recebimento, sem gravá-las no banco.
+// Uso: record -o arquivo [-broker URL] [-topic TÓPICO] [-duration 1h]
+func Record(args []string) error {
+	fs := flag.NewFlagSet("record", flag.ContinueOnError)
+	output := fs.String("o", "", "arquivo de gravação (acrescenta ao fim)")
+	broker := fs.String("broker", mqtt.DefaultBroker, "endereço do broker")
+	topic := fs.String("topic", mqtt.Topic, "tópico (aceita curingas + e #)")
+	duration := fs.Duration("duration", 0, "tempo de gravação (padrão: até Ctrl+C)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		return fmt.Errorf("informe o arquivo de gravação com -o")
+	}
+
+	recorder, err := mqtt.OpenRecorder(*output)
+	if err != nil {
+		return err
+	}
+	defer recorder.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	count, err := mqtt.Record(ctx, *broker, *topic, recorder)
+	if err != nil {
+		return err
+	}
+	slog.Info("Gravação encerrada", "file", *output, "messages", count)
+	return nil
+}
+
+// Replay reenvia uma gravação ao pipeline de ingestão do MQTT, respeitando
+// o intervalo entre as mensagens dividido por -speed (0 = sem espera). Com
+// -scratch as leituras vão para um banco de rascunho criado a partir de
+// tabela.sql; com -alerts as regras de alerta são avaliadas (sem notificações).
+// Uso: replay [-speed 1] [-scratch NOME] [-schema tabela.sql] [-alerts] arquivo
+func Replay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	speed := fs.Float64("speed", 1, "multiplicador de velocidade; 0 envia sem espera")
+	scratch := fs.String("scratch", "", "banco de rascunho (criado se não existir)")
+	schema := fs.String("schema", "tabela.sql", "esquema aplicado ao banco de rascunho")
+	withAlerts := fs.Bool("alerts", false, "avalia as regras de ALERT_RULES_FILE")
+	topic := fs.String("topic", "", "reenvia só as mensagens deste tópico")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("informe o arquivo de gravação")
+	}
+	if *speed < 0 {
+		return fmt.Errorf("velocidade inválida: %g", *speed)
+	}
+
+	db, err := openDatabase(*scratch, *schema)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if *withAlerts {
+		alerts.Setup(ingest.AlertMetrics())
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var previous time.Time
+	messages, readings, failed := 0, 0, 0
+	err = mqtt.ReadRecording(file, func(msg mqtt.RecordedMessage) error {
+		if *topic != "" && msg.Topic != *topic {
+			return nil
+		}
+		if *speed > 0 && !previous.IsZero() {
+			if wait := time.Duration(float64(msg.Received.Sub(previous)) / *speed); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		previous = msg.Received
+
+		messages++
+		saved, err := mqtt.Handle(db, msg.Topic, msg.Bytes(), msg.Received)
+		if err != nil {
+			failed++
+			slog.Error("Mensagem inválida na gravação", "topic", msg.Topic, "received", msg.Received, "err", err)
+		}
+		readings += saved
+		return nil
+	})
+	slog.Info("Replay encerrado", "messages", messages, "readings", readings, "invalid", failed)
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"projeto/app/config"
+	"regexp"
+	"strings"
+)
+
+// scratchName restringe o nome do banco de rascunho, que entra no SQL sem parâmetro
+var scratchName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// openScratch cria, se preciso, um banco de rascunho no mesmo servidor e
+// aplica nele o esquema de schemaPath (tabela.sql). As instruções
+// CREATE DATABASE e USE do arquivo são ignoradas para não trocar de banco
+func openScratch(name, schemaPath string) (*sql.DB, error) {
+	if !scratchName.MatchString(name) {
+		return nil, fmt.Errorf("nome de banco inválido: %s", name)
+	}
+	if name == os.Getenv("MYSQL_DB") {
+		return nil, fmt.Errorf("o banco de rascunho não pode ser o banco principal (%s)", name)
+	}
+	schema, err := os.ReadFile(schemaPath)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o esquema: %w", err)
+	}
+
+	server, err := sql.Open("mysql", config.DatabaseDSNFor(""))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao servidor: %w", err)
+	}
+	_, err = server.Exec("CREATE DATABASE IF NOT EXISTS " + name)
+	server.Close()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar o banco %s: %w", name, err)
+	}
+
+	db, err := sql.Open("mysql", config.DatabaseDSNFor(name))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao banco %s: %w", name, err)
+	}
+	for _, statement := range schemaStatements(string(schema)) {
+		upper := strings.ToUpper(statement)
+		if strings.HasPrefix(upper, "CREATE DATABASE") || strings.HasPrefix(upper, "USE ") {
+			continue
+		}
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("erro ao aplicar o esquema: %w", err)
+		}
+	}
+	return db, nil
+}
+
+// schemaStatements separa o arquivo SQL em instruções, sem os comentários "--"
+func schemaStatements(schema string) []string {
+	var b strings.Builder
+	for _, line := range strings.Split(schema, "\n") {
+		if i := strings.Index(line, "--"); i >= 0 {
+			line = line[:i]
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	var statements []string
+	for _, statement := range strings.Split(b.String(), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// openDatabase abre o banco de rascunho, quando informado, ou o principal
+func openDatabase(scratch, schemaPath string) (*sql.DB, error) {
+	if scratch != "" {
+		db, err := openScratch(scratch, schemaPath)
+		if err == nil {
+			slog.Info("Usando banco de rascunho", "database", scratch)
+		}
+		return db, err
+	}
+	db, err := sql.Open("mysql", config.DatabaseDSN())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao banco: %w", err)
+	}
+	return db, nil
+}
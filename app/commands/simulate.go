@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"projeto/app/mqtt"
+	"projeto/app/simulator"
+	"strings"
+	"time"
+)
+
+// Destino padrão da simulação: o broker local e um tópico fora da produção
+const (
+	simulationBroker = "tcp://localhost:1883"
+	simulationTopic  = "simulacao"
+)
+
+// sameBrokerHost indica se as duas URLs de broker apontam para o mesmo host,
+// ignorando credenciais
+func sameBrokerHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return strings.EqualFold(ua.Hostname(), ub.Hostname())
+}
+
+// Simulate publica leituras SenML de estações virtuais no broker ou as grava
+// direto pelo pipeline de ingestão. Com -from as leituras começam no passado
+// e são geradas sem espera até alcançar o presente; depois seguem em tempo
+// real, a cada -interval dividido por -speed. Por padrão publica no broker
+// local, em um tópico que o painel não assina; o broker de produção, assim
+// como -direct sem -scratch, só é aceito com -allow-production.
+// Uso: simulate [-stations 1] [-prefix sim] [-interval 1m] [-speed 1]
+// [-from AAAA-MM-DD] [-duration 0] [-broker URL] [-topic simulacao]
+// [-direct -scratch NOME] [-dropout 0] [-spike 0]
+func Simulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	count := fs.Int("stations", 1, "quantidade de estações virtuais")
+	prefix := fs.String("prefix", "sim", "nome das estações (prefixo-1, prefixo-2...)")
+	interval := fs.Duration("interval", time.Minute, "intervalo entre leituras de cada estação")
+	speed := fs.Float64("speed", 1, "multiplicador do tempo simulado em tempo real")
+	from := fs.String("from", "", "gera o histórico a partir deste dia (AAAA-MM-DD) antes de seguir em tempo real")
+	duration := fs.Duration("duration", 0, "tempo simulado total (padrão: até Ctrl+C)")
+	broker := fs.String("broker", simulationBroker, "endereço do broker")
+	topic := fs.String("topic", simulationTopic, "tópico de publicação (o painel assina "+mqtt.Topic+")")
+	allowProduction := fs.Bool("allow-production", false, "permite publicar no broker ou gravar no banco de produção")
+	direct := fs.Bool("direct", false, "grava pelo pipeline de ingestão sem passar pelo broker")
+	scratch := fs.String("scratch", "", "com -direct, banco de rascunho (criado se não existir)")
+	schema := fs.String("schema", "tabela.sql", "esquema aplicado ao banco de rascunho")
+	dropout := fs.Float64("dropout", 0, "probabilidade de perder cada leitura")
+	spike := fs.Float64("spike", 0, "probabilidade de um valor absurdo em cada leitura")
+	seed := fs.Int64("seed", 0, "semente aleatória (padrão: relógio)")
+	cfg := simulator.DefaultConfig
+	fs.Float64Var(&cfg.MeanTemperature, "temperature", cfg.MeanTemperature, "temperatura média (°C)")
+	fs.Float64Var(&cfg.TemperatureRange, "temperature-range", cfg.TemperatureRange, "amplitude térmica diária (°C)")
+	fs.Float64Var(&cfg.MeanHumidity, "humidity", cfg.MeanHumidity, "umidade média (%)")
+	fs.Float64Var(&cfg.MeanWind, "wind", cfg.MeanWind, "velocidade média do vento (m/s)")
+	fs.Float64Var(&cfg.RainEventsPerDay, "rain-events", cfg.RainEventsPerDay, "eventos de chuva por dia")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *count < 1 || *interval <= 0 || *speed <= 0 {
+		return fmt.Errorf("estações, intervalo e velocidade devem ser positivos")
+	}
+	if !*direct && !*allowProduction && sameBrokerHost(*broker, mqtt.DefaultBroker) {
+		return fmt.Errorf("o broker informado é o de produção (%s); use -allow-production para publicar dados simulados nele", mqtt.DefaultBroker)
+	}
+	if *direct && *scratch == "" && !*allowProduction {
+		return fmt.Errorf("-direct sem -scratch grava no banco de produção; informe -scratch NOME ou use -allow-production")
+	}
+	cfg.Faults = simulator.Faults{Dropout: *dropout, Spike: *spike}
+	cfg.Seed = *seed
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+
+	now := time.Now()
+	start := now
+	if *from != "" {
+		var err error
+		if start, err = parseDate(*from); err != nil {
+			return err
+		}
+	}
+
+	var send func(topic string, payload []byte, at time.Time) error
+	if *direct {
+		db, err := openDatabase(*scratch, *schema)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		send = func(topic string, payload []byte, at time.Time) error {
+			_, err := mqtt.Handle(db, topic, payload, at)
+			return err
+		}
+	} else {
+		publisher, err := mqtt.NewPublisher(*broker, fmt.Sprintf("GoSimulator-%d", os.Getpid()))
+		if err != nil {
+			return err
+		}
+		defer publisher.Close()
+		send = func(topic string, payload []byte, _ time.Time) error {
+			return publisher.Publish(topic, payload)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	stations := simulator.NewStations(*prefix, *count, cfg)
+	slog.Info("Simulação iniciada", "stations", *count, "from", start.Format(time.RFC3339), "direct", *direct)
+
+	// O relógio simulado anda em tempo real (vezes speed) a partir de agora;
+	// enquanto estiver atrás do presente, por causa de -from, não há espera
+	sent, dropped, spiked := 0, 0, 0
+	wallStart := time.Now()
+	for at := start; *duration == 0 || at.Sub(start) < *duration; at = at.Add(*interval) {
+		simulated := now.Add(time.Duration(float64(time.Since(wallStart)) * *speed))
+		if wait := time.Duration(float64(at.Sub(simulated)) / *speed); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				slog.Info("Simulação encerrada", "sent", sent, "dropped", dropped, "spikes", spiked)
+				return nil
+			}
+		} else if ctx.Err() != nil {
+			break
+		}
+
+		for _, station := range stations {
+			reading, ok := station.Next(at)
+			if !ok {
+				dropped++
+				continue
+			}
+			if reading.Spiked != "" {
+				spiked++
+				slog.Debug("Valor absurdo simulado", "station", station.Name, "metric", reading.Spiked)
+			}
+			payload, err := station.Payload(reading)
+			if err != nil {
+				return err
+			}
+			if err := send(*topic, payload, at); err != nil {
+				slog.Error("Erro ao enviar leitura simulada", "station", station.Name, "err", err)
+				continue
+			}
+			sent++
+		}
+	}
+	slog.Info("Simulação encerrada", "sent", sent, "dropped", dropped, "spikes", spiked)
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"projeto/app/meteorology"
+	"strconv"
+)
+
+// DatabaseDSN retorna a string de conexão com o MySQL a partir de
+// MYSQL_HOST, MYSQL_USER, MYSQL_PASSWORD e MYSQL_DB
+func DatabaseDSN() string {
+	return DatabaseDSNFor(os.Getenv("MYSQL_DB"))
+}
+
+// DatabaseDSNFor retorna a string de conexão com outro banco do mesmo
+// servidor, como os bancos de rascunho do replay. Vazio conecta sem banco
+func DatabaseDSNFor(database string) string {
+	host := os.Getenv("MYSQL_HOST")
+	user := os.Getenv("MYSQL_USER")
+	password := os.Getenv("MYSQL_PASSWORD")
+	return user + ":" + password + "@tcp(" + host + ":3306)/" + database
+}
+
+// GetEnvFloat lê uma variável de ambiente numérica, usando fallback quando
+// ela não existe ou é inválida
+func GetEnvFloat(name string, fallback float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		slog.Warn("Valor inválido em variável de ambiente", "var", name, "value", value)
+		return fallback
+	}
+	return parsed
+}
+
+// SiteConfigured indica se STATION_LATITUDE e STATION_LONGITUDE foram
+// definidas; sem elas Site usa 0, que não serve para cálculos astronômicos
+// como os da ET0
+func SiteConfigured() bool {
+	return os.Getenv("STATION_LATITUDE") != "" && os.Getenv("STATION_LONGITUDE") != ""
+}
+
+// Site retorna a localização da estação a partir de STATION_LATITUDE,
+// STATION_LONGITUDE, STATION_ELEVATION e WIND_SENSOR_HEIGHT
+func Site() meteorology.Site {
+	return meteorology.Site{
+		Latitude:   GetEnvFloat("STATION_LATITUDE", 0),
+		Longitude:  GetEnvFloat("STATION_LONGITUDE", 0),
+		Elevation:  GetEnvFloat("STATION_ELEVATION", 0),
+		WindHeight: GetEnvFloat("WIND_SENSOR_HEIGHT", 2),
+	}
+}
@@ -0,0 +1,134 @@
+// Package devices guarda os dispositivos autorizados a enviar leituras pela
+// ingestão HTTP, cada um com o seu token e, opcionalmente, as estações que
+// pode alimentar
+package devices
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// minTokenLength evita tokens fáceis de adivinhar
+const minTokenLength = 16
+
+// Device é um registrador autorizado. Sem Stations, pode enviar leituras de
+// qualquer estação
+type Device struct {
+	ID       string   `json:"id"`
+	Token    string   `json:"token"`
+	Stations []string `json:"stations,omitempty"`
+	Disabled bool     `json:"disabled,omitempty"`
+}
+
+// Allows indica se o dispositivo pode enviar leituras da estação
+func (d Device) Allows(station string) bool {
+	if len(d.Stations) == 0 {
+		return true
+	}
+	for _, s := range d.Stations {
+		if s == station {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	mu      sync.RWMutex
+	devices []Device
+)
+
+// Parse decodifica e valida a lista de dispositivos
+func Parse(content []byte) ([]Device, error) {
+	var list []Device
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar dispositivos: %w", err)
+	}
+
+	ids := map[string]bool{}
+	tokens := map[string]string{}
+	for i, d := range list {
+		if d.ID == "" {
+			return nil, fmt.Errorf("dispositivo %d sem id", i+1)
+		}
+		if ids[d.ID] {
+			return nil, fmt.Errorf("id de dispositivo repetido: %s", d.ID)
+		}
+		ids[d.ID] = true
+		if len(d.Token) < minTokenLength {
+			return nil, fmt.Errorf("dispositivo %s: o token precisa de pelo menos %d caracteres", d.ID, minTokenLength)
+		}
+		if other, ok := tokens[d.Token]; ok {
+			return nil, fmt.Errorf("dispositivos %s e %s usam o mesmo token", other, d.ID)
+		}
+		tokens[d.Token] = d.ID
+	}
+	return list, nil
+}
+
+// Load lê os dispositivos do arquivo JSON indicado
+func Load(path string) ([]Device, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content)
+}
+
+// Setup carrega os dispositivos de INGEST_DEVICES_FILE (padrão
+// dispositivos.json). Sem arquivo, a ingestão HTTP fica desabilitada
+func Setup() {
+	path := os.Getenv("INGEST_DEVICES_FILE")
+	if path == "" {
+		path = "dispositivos.json"
+	}
+
+	list, err := Load(path)
+	if err != nil {
+		slog.Warn("Dispositivos de ingestão não carregados, ingestão HTTP desabilitada", "file", path, "err", err)
+		return
+	}
+	Set(list)
+	slog.Info("Dispositivos de ingestão carregados", "file", path, "devices", len(list))
+}
+
+// Set troca a lista de dispositivos em uso
+func Set(list []Device) {
+	mu.Lock()
+	defer mu.Unlock()
+	devices = list
+}
+
+// Enabled indica se há algum dispositivo cadastrado
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(devices) > 0
+}
+
+// Authenticate retorna o dispositivo ativo dono do token. Os tokens são
+// comparados pelo hash em tempo constante, percorrendo toda a lista, para
+// não revelar pelo tempo de resposta quais prefixos existem
+func Authenticate(token string) (Device, bool) {
+	if token == "" {
+		return Device{}, false
+	}
+	given := sha256.Sum256([]byte(token))
+
+	mu.RLock()
+	defer mu.RUnlock()
+	var found Device
+	ok := false
+	for _, d := range devices {
+		expected := sha256.Sum256([]byte(d.Token))
+		if subtle.ConstantTimeCompare(given[:], expected[:]) == 1 && !d.Disabled {
+			found, ok = d, true
+		}
+	}
+	return found, ok
+}
@@ -0,0 +1,458 @@
+// Package export gera as exportações de dados em CSV e NDJSON, escrevendo
+// linha a linha conforme o banco retorna, sem carregar o período em memória
+package export
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"projeto/app/meteorology"
+	"projeto/app/qc"
+	"projeto/app/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formatos de saída
+const (
+	CSV    = "csv"
+	NDJSON = "ndjson"
+)
+
+// Resoluções: leituras individuais ou agregados por hora/dia local
+const (
+	Raw  = "raw"
+	Hour = "hour"
+	Day  = "day"
+)
+
+// FlushEvery é a quantidade de linhas entre cada envio parcial da resposta
+const FlushEvery = 500
+
+// ContentTypes associa cada formato ao Content-Type da resposta
+var ContentTypes = map[string]string{
+	CSV:    "text/csv; charset=utf-8",
+	NDJSON: "application/x-ndjson",
+}
+
+// Options define o que exportar. Os valores saem nas unidades de
+// armazenamento: °C, %, mm, m/s, radianos, W/m²
+type Options struct {
+	Format     string
+	Resolution string
+	Metrics    []string // colunas de sensor_data, na ordem de qc.Metrics
+	Station    string   // vazio exporta todas
+	Start      int64
+	End        int64
+	Quality    qc.Filter // valores rejeitados saem vazios
+}
+
+// Parse lê as opções da consulta: format, resolution, metrics (lista separada
+// por vírgulas), station, quality e o período de utils.ParseRange (padrão 24h)
+func Parse(query url.Values, now time.Time) (Options, error) {
+	opts := Options{Format: CSV, Resolution: Raw, Metrics: qc.Metrics, Station: query.Get("station")}
+
+	if value := query.Get("format"); value != "" {
+		if _, ok := ContentTypes[value]; !ok {
+			return opts, fmt.Errorf("formato inválido: %s", value)
+		}
+		opts.Format = value
+	}
+	switch value := query.Get("resolution"); value {
+	case "":
+	case Raw, Hour, Day:
+		opts.Resolution = value
+	default:
+		return opts, fmt.Errorf("resolução inválida: %s", value)
+	}
+
+	if value := query.Get("metrics"); value != "" {
+		requested := map[string]bool{}
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if !isMetric(name) {
+				return opts, fmt.Errorf("métrica desconhecida: %s", name)
+			}
+			requested[name] = true
+		}
+		opts.Metrics = nil
+		for _, metric := range qc.Metrics {
+			if requested[metric] {
+				opts.Metrics = append(opts.Metrics, metric)
+			}
+		}
+	}
+
+	var err error
+	if opts.Quality, err = qc.ParseQuality(query.Get("quality")); err != nil {
+		return opts, err
+	}
+	opts.Start, opts.End, err = utils.ParseRange(query, now, 24*time.Hour)
+	return opts, err
+}
+
+// isMetric indica se o nome é uma das métricas exportáveis
+func isMetric(name string) bool {
+	for _, metric := range qc.Metrics {
+		if metric == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Filename sugere o nome do arquivo para Content-Disposition
+func (o Options) Filename() string {
+	day := func(ts int64) string { return time.Unix(ts, 0).In(utils.Local).Format("20060102") }
+	name := fmt.Sprintf("clima-%s-%s-%s", o.Resolution, day(o.Start), day(o.End))
+	if o.Station != "" {
+		name += "-" + o.Station
+	}
+	return name + "." + o.Format
+}
+
+// Columns retorna o cabeçalho da exportação. Nos agregados, rain_level vira
+// o total do intervalo e wind_direction a direção média vetorial
+func (o Options) Columns() []string {
+	if o.Resolution == Raw {
+		columns := []string{"timestamp", "time", "station"}
+		columns = append(columns, o.Metrics...)
+		return append(columns, "qc_flags")
+	}
+
+	columns := []string{"start", "time", "station", "count"}
+	for _, metric := range o.Metrics {
+		switch metric {
+		case qc.RainLevel:
+			columns = append(columns, metric+"_total")
+		case qc.WindDirection:
+			columns = append(columns, metric+"_mean")
+		default:
+			columns = append(columns, metric+"_avg", metric+"_min", metric+"_max")
+		}
+	}
+	return columns
+}
+
+// rowWriter escreve uma linha no formato de saída. Valores nil ficam vazios
+// no CSV e null no NDJSON
+type rowWriter interface {
+	Row(values []interface{}) error
+	Flush() error
+}
+
+// csvWriter escreve as linhas com cabeçalho
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (c *csvWriter) Row(values []interface{}) error {
+	c.record = c.record[:0]
+	for _, v := range values {
+		c.record = append(c.record, formatCSV(v))
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// formatCSV converte um valor sem perder precisão
+func formatCSV(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case int:
+		return strconv.Itoa(value)
+	case string:
+		return value
+	}
+	return fmt.Sprint(v)
+}
+
+// ndjsonWriter escreve um objeto JSON por linha, com as chaves na ordem das colunas
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []string
+	buf     []byte
+}
+
+func (n *ndjsonWriter) Row(values []interface{}) error {
+	n.buf = append(n.buf[:0], '{')
+	for i, v := range values {
+		if i > 0 {
+			n.buf = append(n.buf, ',')
+		}
+		key, _ := json.Marshal(n.columns[i])
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		n.buf = append(append(append(n.buf, key...), ':'), value...)
+	}
+	n.buf = append(n.buf, '}', '\n')
+	_, err := n.w.Write(n.buf)
+	return err
+}
+
+func (n *ndjsonWriter) Flush() error { return nil }
+
+// Query consulta as leituras da exportação, ordenadas por estação e tempo.
+// Separada de Write para que erros de consulta ainda possam ser respondidos
+// antes de qualquer linha ser enviada
+func Query(db *sql.DB, opts Options) (*sql.Rows, error) {
+	return db.Query(`
+        SELECT timestamp, station, `+strings.Join(opts.Metrics, ", ")+`, qc_flags
+        FROM sensor_data
+        WHERE timestamp BETWEEN ? AND ? AND (? = '' OR station = ?)
+        ORDER BY station, timestamp
+    `, opts.Start, opts.End, opts.Station, opts.Station)
+}
+
+// Write escreve a exportação das linhas de Query em w e fecha rows. flush, se
+// não for nil, é chamado a cada FlushEvery linhas para enviar a resposta
+// parcial. Retorna a quantidade de linhas de dados escritas
+func Write(rows *sql.Rows, w io.Writer, opts Options, flush func()) (int, error) {
+	defer rows.Close()
+
+	columns := opts.Columns()
+	var out rowWriter
+	if opts.Format == NDJSON {
+		out = &ndjsonWriter{w: w, columns: columns}
+	} else {
+		out = &csvWriter{w: csv.NewWriter(w)}
+		header := make([]interface{}, len(columns))
+		for i, c := range columns {
+			header[i] = c
+		}
+		if err := out.Row(header); err != nil {
+			return 0, err
+		}
+	}
+
+	written := 0
+	emit := func(values []interface{}) error {
+		if err := out.Row(values); err != nil {
+			return err
+		}
+		written++
+		if written%FlushEvery == 0 {
+			if err := out.Flush(); err != nil {
+				return err
+			}
+			if flush != nil {
+				flush()
+			}
+		}
+		return nil
+	}
+
+	var agg *aggregator
+	if opts.Resolution != Raw {
+		agg = newAggregator(opts)
+	}
+
+	values := make([]sql.NullFloat64, len(opts.Metrics))
+	dest := make([]interface{}, 0, len(values)+3)
+	var (
+		timestamp int64
+		station   string
+		flags     qc.Flags
+	)
+	dest = append(dest, &timestamp, &station)
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+	dest = append(dest, &flags)
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return written, err
+		}
+		for i, metric := range opts.Metrics {
+			if !opts.Quality.Accept(flags, metric) {
+				values[i].Valid = false
+			}
+		}
+
+		if agg == nil {
+			row := make([]interface{}, 0, len(columns))
+			row = append(row, timestamp, formatTime(timestamp), station)
+			for _, v := range values {
+				if v.Valid {
+					row = append(row, v.Float64)
+				} else {
+					row = append(row, nil)
+				}
+			}
+			if err := emit(append(row, int64(flags))); err != nil {
+				return written, err
+			}
+			continue
+		}
+
+		if done := agg.add(station, timestamp, values); done != nil {
+			if err := emit(done); err != nil {
+				return written, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return written, err
+	}
+	if agg != nil {
+		if done := agg.finish(); done != nil {
+			if err := emit(done); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, out.Flush()
+}
+
+// formatTime formata o instante no fuso local (ISO 8601)
+func formatTime(timestamp int64) string {
+	return time.Unix(timestamp, 0).In(utils.Local).Format(time.RFC3339)
+}
+
+// stats acumula média, mínimo e máximo de uma métrica no intervalo
+type stats struct {
+	sum, min, max float64
+	n             int
+}
+
+func (s *stats) add(v float64) {
+	if s.n == 0 || v < s.min {
+		s.min = v
+	}
+	if s.n == 0 || v > s.max {
+		s.max = v
+	}
+	s.sum += v
+	s.n++
+}
+
+// aggregator agrupa as leituras ordenadas por estação e tempo em intervalos
+// alinhados ao fuso local, emitindo cada intervalo quando o seguinte começa
+type aggregator struct {
+	opts    Options
+	size    int64
+	station string
+	start   int64
+	count   int
+	open    bool
+	metrics []stats
+	rain    float64
+	sin     float64
+	cos     float64
+	dirN    int
+
+	// última leitura válida do pluviômetro da estação, para os incrementos
+	lastRain    meteorology.RainSample
+	hasLastRain bool
+}
+
+func newAggregator(opts Options) *aggregator {
+	size := int64(3600)
+	if opts.Resolution == Day {
+		size = 86400
+	}
+	return &aggregator{opts: opts, size: size, metrics: make([]stats, len(opts.Metrics))}
+}
+
+// bucketStart alinha o instante ao início da hora ou do dia local
+func (a *aggregator) bucketStart(timestamp int64) int64 {
+	_, offset := time.Unix(timestamp, 0).In(utils.Local).Zone()
+	local := timestamp + int64(offset)
+	return local - local%a.size - int64(offset)
+}
+
+// add inclui uma leitura e retorna a linha do intervalo anterior, se ele terminou
+func (a *aggregator) add(station string, timestamp int64, values []sql.NullFloat64) []interface{} {
+	start := a.bucketStart(timestamp)
+	var done []interface{}
+	if a.open && (station != a.station || start != a.start) {
+		done = a.finish()
+	}
+	if station != a.station {
+		a.hasLastRain = false
+	}
+	if !a.open {
+		a.station, a.start, a.open = station, start, true
+	}
+
+	a.count++
+	for i, metric := range a.opts.Metrics {
+		if !values[i].Valid {
+			continue
+		}
+		v := values[i].Float64
+		switch metric {
+		case qc.RainLevel:
+			sample := meteorology.RainSample{Timestamp: timestamp, Level: v}
+			if a.hasLastRain {
+				increments, _ := meteorology.RainIncrements([]meteorology.RainSample{a.lastRain, sample})
+				a.rain += increments[1]
+			}
+			a.lastRain, a.hasLastRain = sample, true
+		case qc.WindDirection:
+			a.sin += math.Sin(v)
+			a.cos += math.Cos(v)
+			a.dirN++
+		}
+		a.metrics[i].add(v)
+	}
+	return done
+}
+
+// finish fecha o intervalo aberto e retorna a sua linha
+func (a *aggregator) finish() []interface{} {
+	if !a.open {
+		return nil
+	}
+	row := []interface{}{a.start, formatTime(a.start), a.station, a.count}
+	for i, metric := range a.opts.Metrics {
+		s := a.metrics[i]
+		switch {
+		case metric == qc.RainLevel:
+			if s.n == 0 {
+				row = append(row, nil)
+			} else {
+				row = append(row, round(a.rain))
+			}
+		case metric == qc.WindDirection:
+			if a.dirN == 0 {
+				row = append(row, nil)
+			} else {
+				row = append(row, round(meteorology.NormalizeAngle(math.Atan2(a.sin, a.cos))))
+			}
+		case s.n == 0:
+			row = append(row, nil, nil, nil)
+		default:
+			row = append(row, round(s.sum/float64(s.n)), s.min, s.max)
+		}
+	}
+
+	a.open, a.count, a.rain, a.sin, a.cos, a.dirN = false, 0, 0, 0, 0, 0
+	for i := range a.metrics {
+		a.metrics[i] = stats{}
+	}
+	return row
+}
+
+// round limita a precisão dos valores calculados
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
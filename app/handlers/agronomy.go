@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"projeto/app/meteorology"
+	"projeto/app/utils"
+	"strconv"
+	"time"
+)
+
+// DegreeDayPoint é o resultado diário de graus-dia e horas de frio
+type DegreeDayPoint struct {
+	Date                 string  `json:"date"`
+	TempMin              float64 `json:"temperature_min"`
+	TempMax              float64 `json:"temperature_max"`
+	GDD                  float64 `json:"gdd"`
+	CumulativeGDD        float64 `json:"cumulative_gdd"`
+	ChillHours           int     `json:"chill_hours"`
+	CumulativeChillHours int     `json:"cumulative_chill_hours"`
+}
+
+// DegreeDaysResponse é a resposta de /api/grausdia e /api/v1/degree-days
+type DegreeDaysResponse struct {
+	Start           int64                   `json:"start"`
+	End             int64                   `json:"end"`
+	Profile         meteorology.CropProfile `json:"profile"`
+	Daily           []DegreeDayPoint        `json:"daily"`
+	TotalGDD        float64                 `json:"total_gdd"`
+	TotalChillHours int                     `json:"total_chill_hours"`
+}
+
+// ApiDegreeDaysHandler retorna graus-dia e horas de frio diários e acumulados
+// a partir de start (ou period, padrão 90d). O perfil vem de crop e pode
+// ser ajustado com base e cap
+func ApiDegreeDaysHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start, end, err := utils.ParseRange(query, time.Now(), 90*24*time.Hour)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	profile := meteorology.CropProfiles["milho"]
+	if crop := query.Get("crop"); crop != "" {
+		var ok bool
+		if profile, ok = meteorology.CropProfiles[crop]; !ok {
+			writeProblem(w, http.StatusBadRequest, "Perfil de cultura desconhecido: "+crop)
+			return
+		}
+	}
+	for param, dest := range map[string]*float64{"base": &profile.BaseTemp, "cap": &profile.CapTemp} {
+		if value := query.Get(param); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				writeProblem(w, http.StatusBadRequest, "Valor inválido para "+param)
+				return
+			}
+			*dest = parsed
+		}
+	}
+	if profile.CapTemp > 0 && profile.CapTemp <= profile.BaseTemp {
+		writeProblem(w, http.StatusBadRequest, "A temperatura de corte deve ser maior que a base")
+		return
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	// Horas de frio por dia local, a partir das médias horárias. Horas sem
+	// temperatura não contam
+	chillHours := map[string]int{}
+	station := requestStation(r)
+	for _, a := range utils.GetHourlyAggregates(db, station, start, end) {
+		if a.Temperature.Valid && meteorology.IsChillHour(a.Temperature.Float64) {
+			chillHours[a.Date]++
+		}
+	}
+
+	daily := []DegreeDayPoint{}
+	var cumulativeGDD float64
+	var cumulativeChill int
+	for _, a := range utils.GetDailyAggregates(db, station, start, end) {
+		// Dias sem mínima e máxima ficam fora da série
+		if !a.TempMin.Valid || !a.TempMax.Valid {
+			continue
+		}
+		gdd := meteorology.GrowingDegreeDays(a.TempMin.Float64, a.TempMax.Float64, profile)
+		cumulativeGDD += gdd
+		cumulativeChill += chillHours[a.Date]
+		daily = append(daily, DegreeDayPoint{
+			Date:                 a.Date,
+			TempMin:              a.TempMin.Float64,
+			TempMax:              a.TempMax.Float64,
+			GDD:                  gdd,
+			CumulativeGDD:        cumulativeGDD,
+			ChillHours:           chillHours[a.Date],
+			CumulativeChillHours: cumulativeChill,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DegreeDaysResponse{
+		Start:           start,
+		End:             end,
+		Profile:         profile,
+		Daily:           daily,
+		TotalGDD:        cumulativeGDD,
+		TotalChillHours: cumulativeChill,
+	})
+}
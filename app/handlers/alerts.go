@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"projeto/app/alerts"
+	"projeto/app/calibration"
+	"projeto/app/stations"
+	"strconv"
+	"time"
+)
+
+// AlertsResponse é a resposta de /api/alertas e /api/v1/alerts
+type AlertsResponse struct {
+	Alerts []alerts.Alert `json:"alerts"`
+	Rules  []alerts.Rule  `json:"rules"`
+}
+
+// StationsResponse é a resposta de /api/estacoes e /api/v1/stations
+type StationsResponse struct {
+	StaleAfterSeconds int64              `json:"stale_after_seconds"`
+	Stations          []stations.Station `json:"stations"`
+}
+
+// CalibrationsResponse é a resposta de /api/calibracoes e /api/v1/calibrations
+type CalibrationsResponse struct {
+	Calibrations calibration.Set `json:"calibrations"`
+}
+
+// ApiAlertsHandler lista os alertas. Por padrão retorna os pendentes e
+// disparados; state filtra por estado e limit limita a quantidade
+func ApiAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	states := []string{alerts.StatePending, alerts.StateFiring}
+	switch state := query.Get("state"); state {
+	case "":
+	case "all":
+		states = nil
+	case alerts.StatePending, alerts.StateFiring, alerts.StateResolved:
+		states = []string{state}
+	default:
+		writeProblem(w, http.StatusBadRequest, "Estado de alerta inválido")
+		return
+	}
+
+	limit := 100
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeProblem(w, http.StatusBadRequest, "Limite inválido")
+			return
+		}
+		limit = parsed
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	list, err := alerts.LoadAlerts(db, states, limit)
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar alertas")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AlertsResponse{
+		Alerts: list,
+		Rules:  alerts.Default().Rules(),
+	})
+}
+
+// ApiStationsHandler lista as estações com o horário da última leitura e se
+// os dados estão desatualizados
+func ApiStationsHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	list, err := stations.List(db, time.Now())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar estações")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(StationsResponse{
+		StaleAfterSeconds: int64(stations.StaleAfter().Seconds()),
+		Stations:          list,
+	})
+}
+
+// ApiCalibrationsHandler lista as calibrações cadastradas
+func ApiCalibrationsHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	set, err := calibration.Load(db)
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar calibrações")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CalibrationsResponse{Calibrations: set})
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// bearerToken extrai o token do cabeçalho Authorization: Bearer
+func bearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// authorize confere o token Bearer com o esperado, em tempo constante. Sem
+// token configurado a rota fica desabilitada
+func authorize(w http.ResponseWriter, r *http.Request, expected string) bool {
+	if expected == "" {
+		writeProblem(w, http.StatusServiceUnavailable, "Rota desabilitada: nenhum token configurado")
+		return false
+	}
+	token := bearerToken(r)
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		unauthorized(w)
+		return false
+	}
+	return true
+}
+
+// unauthorized responde 401 pedindo um token Bearer
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="clima"`)
+	writeProblem(w, http.StatusUnauthorized, "Token ausente ou inválido")
+}
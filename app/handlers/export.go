@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"projeto/app/export"
+	"time"
+)
+
+// ApiExportHandler exporta leituras ou agregados em CSV ou NDJSON. As linhas
+// são enviadas conforme chegam do banco, então um erro no meio da exportação
+// só pode ser registrado no log
+func ApiExportHandler(w http.ResponseWriter, r *http.Request) {
+	opts, err := export.Parse(r.URL.Query(), time.Now())
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	rows, err := export.Query(db, opts)
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar dados")
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentTypes[opts.Format])
+	w.Header().Set("Content-Disposition", `attachment; filename="`+opts.Filename()+`"`)
+	controller := http.NewResponseController(w)
+	written, err := export.Write(rows, w, opts, func() {
+		if err := controller.Flush(); err != nil {
+			slog.Debug("Resposta sem suporte a flush", "err", err)
+		}
+	})
+	if err != nil {
+		slog.Error("Erro durante a exportação", "err", err, "rows", written)
+		return
+	}
+	slog.Info("Exportação concluída", "format", opts.Format, "resolution", opts.Resolution,
+		"station", opts.Station, "rows", written)
+}
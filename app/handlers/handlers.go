@@ -4,73 +4,153 @@ import (
 	"database/sql"
 	"encoding/json"
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
+	"projeto/app/config"
+	"projeto/app/i18n"
+	"projeto/app/ingest"
+	"projeto/app/meteorology"
+	"projeto/app/mqtt"
+	"projeto/app/qc"
+	"projeto/app/stations"
+	"projeto/app/summary"
+	"projeto/app/units"
 	"projeto/app/utils"
 	"time"
 )
 
 // DatabaseConfig retorna a configuração de conexão com o banco
 func DatabaseConfig() string {
-	host := os.Getenv("MYSQL_HOST")
-	user := os.Getenv("MYSQL_USER")
-	password := os.Getenv("MYSQL_PASSWORD")
-	database := os.Getenv("MYSQL_DB")
-	return user + ":" + password + "@tcp(" + host + ":3306)/" + database
+	return config.DatabaseDSN()
 }
 
 // Index Handler para a rota principal
 func Index(templates *template.Template) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		templates.ExecuteTemplate(w, "index.html", nil)
+		system, err := units.FromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		db, err := sql.Open("mysql", DatabaseConfig())
+		if err != nil {
+			slog.Error("Erro ao conectar ao banco", "err", err)
+			http.Error(w, "Erro interno", http.StatusInternalServerError)
+			return
+		}
+		defer db.Close()
+
+		currentData, previousData := utils.GetMySQLData(db)
+		templates.ExecuteTemplate(w, "index.html", utils.PrepareTemplateData(currentData, previousData, system, i18n.FromRequest(r)))
+	}
+}
+
+// requestUnits lê as unidades pedidas, respondendo 400 se forem inválidas
+func requestUnits(w http.ResponseWriter, r *http.Request) (units.System, bool) {
+	system, err := units.FromRequest(r)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return system, false
 	}
+	return system, true
 }
 
 func ApiIndexHandler(w http.ResponseWriter, r *http.Request) {
+	system, ok := requestUnits(w, r)
+	if !ok {
+		return
+	}
+
 	db, err := sql.Open("mysql", DatabaseConfig())
 	if err != nil {
-		respondWithError(w, "Erro ao conectar ao banco", http.StatusInternalServerError)
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
 		return
 	}
 	defer db.Close()
 
 	currentData, previousData := utils.GetMySQLData(db)
-	context := utils.PrepareAPIData(currentData, previousData)
+	context := utils.PrepareAPIData(currentData, previousData, system, i18n.FromRequest(r))
+
+	// Indicadores de qualidade dos valores da leitura atual
+	flags, _ := currentData["qc_flags"].(int64)
+	context["quality"] = qc.Flags(flags).Names()
+
+	// Idade da última leitura, para indicar dados desatualizados
+	now := time.Now()
+	lastSeen := int64(0)
+	if currentData != nil {
+		lastSeen, _ = currentData["timestamp"].(int64)
+	}
+	context["last_seen"] = lastSeen
+	context["data_age_seconds"] = now.Unix() - lastSeen
+	context["stale"] = stations.IsStale(lastSeen, now)
+
+	// Taxa e acumulados do pluviômetro da estação da leitura atual
+	station := ingest.DefaultStation
+	if currentData != nil {
+		station, _ = currentData["station"].(string)
+	}
+	rain := utils.GetRainAccumulation(db, station, time.Now())
+	context["rain_rate"] = system.Precipitation.FromMM(rain.Rate)
+	context["rain_last_hour"] = system.Precipitation.FromMM(rain.LastHour)
+	context["rain_today"] = system.Precipitation.FromMM(rain.Today)
+	context["rain_storm"] = system.Precipitation.FromMM(rain.Storm)
+	context["rain_storm_start"] = rain.StormStart
+
+	// Indica se a leitura atual bate ou iguala algum recorde anterior a hoje
+	context["records"] = []summary.RecordFlag{}
+	if currentData != nil {
+		today := time.Now().In(utils.Local)
+		almanac, err := summary.LoadAlmanac(db, station, today.Format("2006-01-02"))
+		if err != nil {
+			slog.Error("Erro ao calcular recordes", "err", err)
+		} else {
+			context["records"] = summary.CheckRecords(almanac, int(today.Month()), summary.CurrentReading{
+				Temperature: utils.GetFloatFromMap(currentData, "temperature"),
+				WindGust:    currentGust(currentData),
+				UVIndex:     utils.GetFloatFromMap(currentData, "uv_index"),
+				RainToday:   rain.Today,
+			})
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(context); err != nil {
-		respondWithError(w, "Erro ao serializar dados", http.StatusInternalServerError)
+		writeProblem(w, http.StatusInternalServerError, "Erro ao serializar dados")
 	}
 }
 
 func Dashboard(templates *template.Template) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		system, err := units.FromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		db, err := sql.Open("mysql", DatabaseConfig())
 		if err != nil {
-			log.Printf("Erro ao conectar ao banco: %v", err)
+			slog.Error("Erro ao conectar ao banco", "err", err)
 			http.Error(w, "Erro interno", http.StatusInternalServerError)
 			return
 		}
 		defer db.Close()
 
-		// Cálculo do período (UTC-3)
-		now := time.Now().UTC()
-		localNow := now.Add(-3 * time.Hour)
-		startDay := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, time.UTC)
-		startTimestamp := startDay.Unix() + 3*3600
-		endTimestamp := startTimestamp + 24*3600
+		// Período do dia local (UTC-3)
+		startTimestamp, endTimestamp := utils.TodayRange(time.Now())
+		station := requestStation(r)
 
 		// Buscar dados
 		query := `
             SELECT timestamp, temperature, humidity, rain_level, average_wind_speed 
             FROM sensor_data 
-            WHERE timestamp BETWEEN ? AND ? 
+            WHERE station = ? AND timestamp BETWEEN ? AND ? 
             ORDER BY timestamp
         `
-		rows, err := db.Query(query, startTimestamp, endTimestamp)
+		rows, err := db.Query(query, station, startTimestamp, endTimestamp)
 		if err != nil {
-			log.Printf("Erro ao buscar dados: %v", err)
+			slog.Error("Erro ao buscar dados", "err", err)
 			http.Error(w, "Erro interno", http.StatusInternalServerError)
 			return
 		}
@@ -92,7 +172,7 @@ func Dashboard(templates *template.Template) http.HandlerFunc {
 			var temp, hum, rain, wind sql.NullFloat64
 
 			if err := rows.Scan(&timestamp, &temp, &hum, &rain, &wind); err != nil {
-				log.Printf("Erro ao processar linha: %v", err)
+				slog.Error("Erro ao processar linha", "err", err)
 				continue
 			}
 
@@ -101,101 +181,140 @@ func Dashboard(templates *template.Template) http.HandlerFunc {
 			sensorData.Timestamps = append(sensorData.Timestamps, formattedTime)
 
 			// Popular dados com validação de NULL
-			addNullable := func(src sql.NullFloat64, dest *[]float64) {
+			addNullable := func(src sql.NullFloat64, dest *[]float64, convert func(float64) float64) {
 				if src.Valid {
-					*dest = append(*dest, src.Float64)
+					*dest = append(*dest, convert(src.Float64))
 				} else {
 					*dest = append(*dest, 0.0)
 				}
 			}
 
-			addNullable(temp, &sensorData.Temperature)
-			addNullable(hum, &sensorData.Humidity)
-			addNullable(rain, &sensorData.RainLevel)
-
-			// Converter m/s para km/h
-			if wind.Valid {
-				sensorData.WindSpeed = append(sensorData.WindSpeed, wind.Float64*3.6)
-			} else {
-				sensorData.WindSpeed = append(sensorData.WindSpeed, 0.0)
-			}
+			// Converter para as unidades escolhidas
+			addNullable(temp, &sensorData.Temperature, system.Temperature.FromCelsius)
+			addNullable(hum, &sensorData.Humidity, func(v float64) float64 { return v })
+			addNullable(rain, &sensorData.RainLevel, system.Precipitation.FromMM)
+			addNullable(wind, &sensorData.WindSpeed, system.Speed.FromMS)
 		}
 
 		// Serializar para JSON
 		sensorDataJSON, err := json.Marshal(sensorData)
 		if err != nil {
-			log.Printf("Erro ao serializar JSON: %v", err)
+			slog.Error("Erro ao serializar JSON", "err", err)
 			http.Error(w, "Erro interno", http.StatusInternalServerError)
 			return
 		}
 
+		// Acumulados de chuva na unidade escolhida
+		rain := utils.GetRainAccumulation(db, station, time.Now())
+		rain.Rate = system.Precipitation.FromMM(rain.Rate)
+		rain.LastHour = system.Precipitation.FromMM(rain.LastHour)
+		rain.Today = system.Precipitation.FromMM(rain.Today)
+		rain.Storm = system.Precipitation.FromMM(rain.Storm)
+
 		// Passar dados para o template
 		templates.ExecuteTemplate(w, "dashboard.html", map[string]interface{}{
-			"SensorData": template.JS(sensorDataJSON), // Dados completos para gráficos
+			"SensorData":        template.JS(sensorDataJSON), // Dados completos para gráficos
+			"Rain":              rain,
+			"TemperatureUnit":   system.Temperature.Symbol(),
+			"SpeedUnit":         system.Speed.Symbol(),
+			"PrecipitationUnit": system.Precipitation.Symbol(),
+			"Lang":              i18n.FromRequest(r),
 		})
 	}
 }
 
 func ApiDashboardHandler(w http.ResponseWriter, r *http.Request) {
+	quality, err := qc.ParseQuality(r.URL.Query().Get("quality"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	system, ok := requestUnits(w, r)
+	if !ok {
+		return
+	}
+
 	db, err := sql.Open("mysql", DatabaseConfig())
 	if err != nil {
-		respondWithError(w, "Erro ao conectar ao banco", http.StatusInternalServerError)
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
 		return
 	}
 	defer db.Close()
 
-	// Cálculo de timestamps (mesmo código da Dashboard)
-	now := time.Now().UTC()
-	localNow := now.Add(-3 * time.Hour)
-	startDay := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, time.UTC)
-	startTimestamp := startDay.Unix() + 3*3600
-	endTimestamp := startTimestamp + 24*3600
+	// Período do dia local (UTC-3)
+	startTimestamp, endTimestamp := utils.TodayRange(time.Now())
 
 	rows, err := db.Query(`
-        SELECT timestamp, temperature, humidity, rain_level, average_wind_speed 
+        SELECT timestamp, temperature, humidity, rain_level, average_wind_speed, qc_flags
         FROM sensor_data 
-        WHERE timestamp BETWEEN ? AND ? 
+        WHERE station = ? AND timestamp BETWEEN ? AND ? 
         ORDER BY timestamp
-    `, startTimestamp, endTimestamp)
+    `, requestStation(r), startTimestamp, endTimestamp)
 
 	if err != nil {
-		respondWithError(w, "Erro ao buscar dados", http.StatusInternalServerError)
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar dados")
 		return
 	}
 	defer rows.Close()
 
-	// Processamento igual à Dashboard
+	// Processamento igual à Dashboard, com null nas leituras ausentes ou
+	// rejeitadas para que todas as séries tenham o tamanho de Timestamps
 	var sensorData struct {
-		Timestamps  []string  `json:"timestamps"`
-		Temperature []float64 `json:"temperature"`
-		Humidity    []float64 `json:"humidity"`
-		RainLevel   []float64 `json:"rain_level"`
-		WindSpeed   []float64 `json:"wind_speed"`
+		Timestamps  []string          `json:"timestamps"`
+		Temperature []*float64        `json:"temperature"`
+		Humidity    []*float64        `json:"humidity"`
+		RainLevel   []*float64        `json:"rain_level"`
+		WindSpeed   []*float64        `json:"wind_speed"`
+		DewPoint    []*float64        `json:"dew_point"`
+		HeatIndex   []*float64        `json:"heat_index"`
+		WindChill   []*float64        `json:"wind_chill"`
+		FeelsLike   []*float64        `json:"feels_like"`
+		Units       map[string]string `json:"units"`
 	}
+	sensorData.Units = system.Labels()
 
 	for rows.Next() {
 		var timestamp int64
 		var temperature, humidity, rainLevel, windSpeed sql.NullFloat64
+		var flags qc.Flags
 
-		if err := rows.Scan(&timestamp, &temperature, &humidity, &rainLevel, &windSpeed); err != nil {
-			log.Printf("Erro ao processar linha: %v", err)
+		if err := rows.Scan(&timestamp, &temperature, &humidity, &rainLevel, &windSpeed, &flags); err != nil {
+			slog.Error("Erro ao processar linha", "err", err)
 			continue
 		}
 
+		// Valores rejeitados pelo filtro de qualidade são tratados como ausentes
+		temperature.Valid = temperature.Valid && quality.Accept(flags, qc.Temperature)
+		humidity.Valid = humidity.Valid && quality.Accept(flags, qc.Humidity)
+		rainLevel.Valid = rainLevel.Valid && quality.Accept(flags, qc.RainLevel)
+		windSpeed.Valid = windSpeed.Valid && quality.Accept(flags, qc.WindSpeed)
+
 		formattedTime := time.Unix(timestamp-3*3600, 0).Format("15:04") // UTC-3
 		sensorData.Timestamps = append(sensorData.Timestamps, formattedTime)
-		if temperature.Valid {
-			sensorData.Temperature = append(sensorData.Temperature, temperature.Float64)
-		}
-		if humidity.Valid {
-			sensorData.Humidity = append(sensorData.Humidity, humidity.Float64)
-		}
-		if rainLevel.Valid {
-			sensorData.RainLevel = append(sensorData.RainLevel, rainLevel.Float64)
+		sensorData.Temperature = append(sensorData.Temperature, nullable(temperature, true, system.Temperature.FromCelsius))
+		sensorData.Humidity = append(sensorData.Humidity, nullable(humidity, true, func(v float64) float64 { return v }))
+		sensorData.RainLevel = append(sensorData.RainLevel, nullable(rainLevel, true, system.Precipitation.FromMM))
+		sensorData.WindSpeed = append(sensorData.WindSpeed, nullable(windSpeed, true, system.Speed.FromMS))
+
+		// Índices de conforto derivados da leitura; os que dependem do vento
+		// só existem com o vento aceito
+		var dewPoint, heatIndex, windChill, feelsLike sql.NullFloat64
+		windKMH := units.MSToKMH(windSpeed.Float64)
+		if temperature.Valid && humidity.Valid {
+			dewPoint = sql.NullFloat64{Float64: meteorology.DewPoint(temperature.Float64, humidity.Float64), Valid: true}
+			heatIndex = sql.NullFloat64{Float64: meteorology.HeatIndex(temperature.Float64, humidity.Float64), Valid: true}
+			if windSpeed.Valid {
+				feelsLike = sql.NullFloat64{Float64: meteorology.FeelsLike(temperature.Float64, humidity.Float64, windKMH), Valid: true}
+			}
 		}
-		if windSpeed.Valid {
-			sensorData.WindSpeed = append(sensorData.WindSpeed, windSpeed.Float64*3.6) // m/s para km/h
+		if temperature.Valid && windSpeed.Valid {
+			windChill = sql.NullFloat64{Float64: meteorology.WindChill(temperature.Float64, windKMH), Valid: true}
 		}
+		toUnit := system.Temperature.FromCelsius
+		sensorData.DewPoint = append(sensorData.DewPoint, nullable(dewPoint, true, toUnit))
+		sensorData.HeatIndex = append(sensorData.HeatIndex, nullable(heatIndex, true, toUnit))
+		sensorData.WindChill = append(sensorData.WindChill, nullable(windChill, true, toUnit))
+		sensorData.FeelsLike = append(sensorData.FeelsLike, nullable(feelsLike, true, toUnit))
 	}
 	// ... (código de processamento igual à Dashboard)
 
@@ -205,31 +324,33 @@ func ApiDashboardHandler(w http.ResponseWriter, r *http.Request) {
 
 func PlotData(templates *template.Template) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		system, err := units.FromRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		db, err := sql.Open("mysql", DatabaseConfig())
 		if err != nil {
-			log.Printf("Erro ao conectar ao banco: %v", err)
+			slog.Error("Erro ao conectar ao banco", "err", err)
 			http.Error(w, "erro interno", http.StatusInternalServerError)
 			return
 		}
 		defer db.Close()
 
-		// calculo do inicio e fim do dia (UTC-3)
-		now := time.Now().UTC()
-		localNow := now.Add(-3 * time.Hour) //UTC-3
-		startDay := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, time.UTC)
-		startTimeStamp := startDay.Unix() + 3*3600
-		endTimeStamp := startTimeStamp + 24*3600
+		// Período do dia local (UTC-3)
+		startTimeStamp, endTimeStamp := utils.TodayRange(time.Now())
 
 		// Buscar dados no banco
 		query := `
 			SELECT timestamp, temperature
 			FROM sensor_data
-			WHERE timestamp BETWEEN ? AND ?
+			WHERE station = ? AND timestamp BETWEEN ? AND ?
 			ORDER BY timestamp
 		`
-		rows, err := db.Query(query, startTimeStamp, endTimeStamp)
+		rows, err := db.Query(query, requestStation(r), startTimeStamp, endTimeStamp)
 		if err != nil {
-			log.Printf("Erro ao conectar ao banco: %v", err)
+			slog.Error("Erro ao conectar ao banco", "err", err)
 			http.Error(w, "erro interno", http.StatusInternalServerError)
 			return
 		}
@@ -243,14 +364,14 @@ func PlotData(templates *template.Template) http.HandlerFunc {
 			var temperature sql.NullFloat64
 
 			if err := rows.Scan(&timestamp, &temperature); err != nil {
-				log.Printf("Erro ao processar linha: %v", err)
+				slog.Error("Erro ao processar linha", "err", err)
 				continue
 			}
 
 			formattedTime := time.Unix(timestamp-3*3600, 0).Format("15:04") // UTC-3
 			timestamps = append(timestamps, formattedTime)
 			if temperature.Valid {
-				temperatures = append(temperatures, temperature.Float64)
+				temperatures = append(temperatures, system.Temperature.FromCelsius(temperature.Float64))
 			}
 		}
 
@@ -280,37 +401,45 @@ func PlotData(templates *template.Template) http.HandlerFunc {
 			"MaxTemperature":     maxTemperature,
 			"MinTemperature":     minTemperature,
 			"SensorData":         template.JS(sensorDataJSON), // Usar template.JS
+			"TemperatureUnit":    system.Temperature.Symbol(),
+			"Lang":               i18n.FromRequest(r),
 		})
 	}
 }
 
 func ApiTemperatureHandler(w http.ResponseWriter, r *http.Request) {
+	quality, err := qc.ParseQuality(r.URL.Query().Get("quality"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	system, ok := requestUnits(w, r)
+	if !ok {
+		return
+	}
+
 	db, err := sql.Open("mysql", DatabaseConfig())
 	if err != nil {
-		log.Printf("Erro ao conectar ao banco: %v", err)
-		respondWithError(w, "Erro interno do servidor", http.StatusInternalServerError)
+		slog.Error("Erro ao conectar ao banco", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Erro interno do servidor")
 		return
 	}
 	defer db.Close()
 
-	// Cálculo do período (UTC-3)
-	now := time.Now().UTC()
-	localNow := now.Add(-3 * time.Hour)
-	startDay := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, time.UTC)
-	startTimestamp := startDay.Unix() + 3*3600
-	endTimestamp := startTimestamp + 24*3600
+	// Período do dia local (UTC-3)
+	startTimestamp, endTimestamp := utils.TodayRange(time.Now())
 
 	// Buscar dados
 	rows, err := db.Query(`
-        SELECT timestamp, temperature 
+        SELECT timestamp, temperature, qc_flags
         FROM sensor_data 
-        WHERE timestamp BETWEEN ? AND ? 
+        WHERE station = ? AND timestamp BETWEEN ? AND ? 
         ORDER BY timestamp
-    `, startTimestamp, endTimestamp)
+    `, requestStation(r), startTimestamp, endTimestamp)
 
 	if err != nil {
-		log.Printf("Erro na consulta: %v", err)
-		respondWithError(w, "Erro ao buscar dados", http.StatusInternalServerError)
+		slog.Error("Erro na consulta", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar dados")
 		return
 	}
 	defer rows.Close()
@@ -323,33 +452,38 @@ func ApiTemperatureHandler(w http.ResponseWriter, r *http.Request) {
 		Average      float64   `json:"average_temperature"`
 		Max          float64   `json:"max_temperature"`
 		Min          float64   `json:"min_temperature"`
+		Unit         string    `json:"unit"`
 		Error        string    `json:"error,omitempty"`
 	}
+	response.Unit = system.Temperature.Symbol()
 
 	var temps []float64
 
 	for rows.Next() {
 		var timestamp int64
 		var temp sql.NullFloat64
+		var flags qc.Flags
 
-		if err := rows.Scan(&timestamp, &temp); err != nil {
-			log.Printf("Erro ao ler linha: %v", err)
+		if err := rows.Scan(&timestamp, &temp, &flags); err != nil {
+			slog.Error("Erro ao ler linha", "err", err)
 			continue
 		}
+		temp.Valid = temp.Valid && quality.Accept(flags, qc.Temperature)
 
 		// Converter timestamp para hora local (UTC-3)
 		formattedTime := time.Unix(timestamp-3*3600, 0).Format("15:04")
 		response.Timestamps = append(response.Timestamps, formattedTime)
 
 		if temp.Valid {
-			response.Temperatures = append(response.Temperatures, temp.Float64)
-			temps = append(temps, temp.Float64)
+			value := system.Temperature.FromCelsius(temp.Float64)
+			response.Temperatures = append(response.Temperatures, value)
+			temps = append(temps, value)
 		}
 	}
 
 	// Verificar dados
 	if len(temps) == 0 {
-		respondWithError(w, "Nenhum dado de temperatura disponível", http.StatusNotFound)
+		writeProblem(w, http.StatusNotFound, "Nenhum dado de temperatura disponível")
 		return
 	}
 
@@ -362,13 +496,26 @@ func ApiTemperatureHandler(w http.ResponseWriter, r *http.Request) {
 	// Enviar resposta
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		log.Printf("Erro ao serializar resposta: %v", err)
-		respondWithError(w, "Erro interno", http.StatusInternalServerError)
+		slog.Error("Erro ao serializar resposta", "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Erro interno")
 	}
 }
-func respondWithError(w http.ResponseWriter, message string, code int) {
-	log.Println(message)
+
+// HealthResponse é a resposta de /health e /api/v1/health
+type HealthResponse struct {
+	Status string                `json:"status"` // "ok" ou "degraded"
+	MQTT   mqtt.ConnectionStatus `json:"mqtt"`
+}
+
+// HealthHandler informa o estado do serviço e da conexão com o broker MQTT
+func HealthHandler(w http.ResponseWriter, r *http.Request) {
+	mqttStatus := mqtt.Status()
+
+	state := "ok"
+	if !mqttStatus.Connected {
+		state = "degraded"
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	json.NewEncoder(w).Encode(HealthResponse{Status: state, MQTT: mqttStatus})
 }
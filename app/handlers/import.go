@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"projeto/app/ingest"
+	"strconv"
+	"strings"
+)
+
+// MaxImportSize limita o tamanho do arquivo enviado para importação
+const MaxImportSize = 64 << 20
+
+// ApiImportHandler importa um arquivo CSV ou SenML enviado no corpo ou no
+// campo "file" de um formulário multipart. Exige o token de IMPORT_TOKEN
+func ApiImportHandler(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r, os.Getenv("IMPORT_TOKEN")) {
+		return
+	}
+
+	query := r.URL.Query()
+	opts := ingest.ImportOptions{Format: query.Get("format"), Station: query.Get("station")}
+	if value := query.Get("dry_run"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, "Valor inválido para dry_run")
+			return
+		}
+		opts.DryRun = dryRun
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImportSize)
+	var body io.Reader = r.Body
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "multipart/form-data":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, "Formulário sem o campo file")
+			return
+		}
+		defer file.Close()
+		body = file
+	case opts.Format == "" && mediaType == "text/csv":
+		opts.Format = ingest.FormatCSV
+	case opts.Format == "" && strings.HasSuffix(mediaType, "json"):
+		opts.Format = ingest.FormatSenML
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	report, err := ingest.Import(db, body, opts)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeProblem(w, http.StatusRequestEntityTooLarge, "Arquivo maior que o limite de importação")
+		return
+	case errors.Is(err, ingest.ErrInvalidFile):
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		slog.Error("Erro ao importar dados", "format", opts.Format, "station", opts.Station,
+			"imported", report.Imported, "err", err)
+		writeProblem(w, http.StatusInternalServerError, "Erro ao importar dados")
+		return
+	}
+	writeJSON(w, report)
+}
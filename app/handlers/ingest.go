@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"projeto/app/devices"
+	"projeto/app/ingest"
+	"projeto/app/mqtt"
+	"time"
+)
+
+// MaxIngestSize limita o corpo de uma requisição de ingestão
+const MaxIngestSize = 1 << 20
+
+// maxClockSkew é quanto uma leitura pode estar à frente do relógio do
+// servidor; datas futuras travariam a detecção de estação sem dados
+const maxClockSkew = 5 * time.Minute
+
+// IngestResponse resume as leituras recebidas pela ingestão HTTP
+type IngestResponse struct {
+	Device   string   `json:"device"`
+	Readings int      `json:"readings"` // leituras decodificadas
+	Saved    int      `json:"saved"`
+	Skipped  int      `json:"skipped"` // registros SenML inválidos ignorados
+	Stations []string `json:"stations"`
+}
+
+// ApiIngestHandler recebe o mesmo SenML publicado no tópico MQTT, para
+// registradores que só fazem POST HTTP: um pacote, um array de pacotes ou um
+// pacote por linha. As leituras seguem o caminho das mensagens MQTT
+// (calibração, controle de qualidade, gravação, resumos e alertas). Exige o
+// token de um dispositivo de INGEST_DEVICES_FILE, que pode estar limitado a
+// algumas estações. A gravação substitui leituras com a mesma estação e
+// timestamp, então reenviar após uma falha é seguro. Só um pacote por
+// requisição pode omitir o tempo, pois os demais cairiam no mesmo instante
+func ApiIngestHandler(w http.ResponseWriter, r *http.Request) {
+	if !devices.Enabled() {
+		writeProblem(w, http.StatusServiceUnavailable, "Rota desabilitada: nenhum dispositivo cadastrado")
+		return
+	}
+	device, ok := devices.Authenticate(bearerToken(r))
+	if !ok {
+		unauthorized(w)
+		return
+	}
+
+	received := time.Now()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxIngestSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeProblem(w, http.StatusRequestEntityTooLarge, "Corpo maior que o limite de ingestão")
+		return
+	} else if err != nil {
+		writeProblem(w, http.StatusBadRequest, "Erro ao ler o corpo da requisição")
+		return
+	}
+
+	result, err := ingest.ReadSenML(bytes.NewReader(body), received.Unix())
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	readings := result.Readings
+	if len(readings) == 0 {
+		writeProblem(w, http.StatusBadRequest, "Nenhuma leitura reconhecida no corpo")
+		return
+	}
+	if result.Untimed > 1 {
+		writeProblem(w, http.StatusBadRequest, fmt.Sprintf("%d pacotes sem tempo absoluto; informe bt em cada pacote", result.Untimed))
+		return
+	}
+	limit := received.Add(maxClockSkew).Unix()
+	for _, reading := range readings {
+		if reading.Timestamp > limit {
+			writeProblem(w, http.StatusBadRequest, fmt.Sprintf("Leitura da estação %s no futuro: %s",
+				reading.Station, time.Unix(reading.Timestamp, 0).UTC().Format(time.RFC3339)))
+			return
+		}
+	}
+
+	response := IngestResponse{Device: device.ID, Readings: len(readings), Skipped: result.Skipped, Stations: []string{}}
+	seen := map[string]bool{}
+	for _, reading := range readings {
+		if seen[reading.Station] {
+			continue
+		}
+		if !device.Allows(reading.Station) {
+			writeProblem(w, http.StatusForbidden, fmt.Sprintf("O dispositivo %s não pode enviar leituras da estação %s", device.ID, reading.Station))
+			return
+		}
+		seen[reading.Station] = true
+		response.Stations = append(response.Stations, reading.Station)
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	response.Saved = mqtt.Store(db, readings)
+	slog.Debug("Leituras recebidas por HTTP", "device", device.ID, "readings", response.Readings, "saved", response.Saved)
+	if response.Saved < response.Readings {
+		writeProblem(w, http.StatusInternalServerError, fmt.Sprintf("%d de %d leituras não foram gravadas", response.Readings-response.Saved, response.Readings))
+		return
+	}
+	writeJSON(w, response)
+}
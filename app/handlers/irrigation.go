@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"projeto/app/config"
+	"projeto/app/i18n"
+	"projeto/app/meteorology"
+	"projeto/app/utils"
+	"time"
+)
+
+// ET0Point é um valor da série de evapotranspiração de referência
+type ET0Point struct {
+	Start int64   `json:"start"`
+	Date  string  `json:"date"`
+	ET0   float64 `json:"et0"`
+}
+
+// ET0Response é a resposta de /api/et0 e /api/v1/et0
+type ET0Response struct {
+	Start     int64      `json:"start"`
+	End       int64      `json:"end"`
+	Latitude  float64    `json:"latitude"`
+	Elevation float64    `json:"elevation"`
+	Hourly    []ET0Point `json:"hourly"`
+	Daily     []ET0Point `json:"daily"`
+	Total     float64    `json:"total"`
+	Unit      string     `json:"unit"`
+}
+
+// Irrigation renderiza a página de irrigação, que consome /api/et0
+func Irrigation(templates *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		templates.ExecuteTemplate(w, "irrigacao.html", map[string]interface{}{
+			"Site": config.Site(),
+			"Lang": i18n.FromRequest(r),
+		})
+	}
+}
+
+// ApiET0Handler retorna a ET0 (FAO-56 Penman-Monteith) horária (mm/h) e
+// diária (mm/dia) no período pedido por start/end ou period (padrão 7d).
+// A série diária só traz dias completos: o de hoje e os cortados pelo início
+// ou fim do período ficam de fora. Com rain=in (ou units=imperial) os
+// valores são dados em polegadas. Sem a localização da estação configurada
+// responde 503
+func ApiET0Handler(w http.ResponseWriter, r *http.Request) {
+	if !config.SiteConfigured() {
+		writeProblem(w, http.StatusServiceUnavailable, "Localização da estação não configurada")
+		return
+	}
+	now := time.Now()
+	start, end, err := utils.ParseRange(r.URL.Query(), now, 7*24*time.Hour)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	system, ok := requestUnits(w, r)
+	if !ok {
+		return
+	}
+	toUnit := system.Precipitation.FromMM
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	site := config.Site()
+	station := requestStation(r)
+
+	// Horas e dias sem alguma das variáveis da equação ficam fora do cálculo
+	var hourlyAggregates []utils.Aggregate
+	var inputs []meteorology.ETHourlyInput
+	for _, a := range utils.GetHourlyAggregates(db, station, start, end) {
+		if !a.Temperature.Valid || !a.Humidity.Valid || !a.WindSpeed.Valid || !a.SolarRadiation.Valid {
+			continue
+		}
+		hourlyAggregates = append(hourlyAggregates, a)
+		inputs = append(inputs, meteorology.ETHourlyInput{
+			Start:          time.Unix(a.Start, 0).In(utils.Local),
+			Temperature:    a.Temperature.Float64,
+			Humidity:       a.Humidity.Float64,
+			WindSpeed:      a.WindSpeed.Float64,
+			SolarRadiation: a.SolarRadiation.Float64,
+		})
+	}
+	hourly := []ET0Point{}
+	for i, et0 := range meteorology.HourlyET0(site, inputs) {
+		a := hourlyAggregates[i]
+		hourly = append(hourly, ET0Point{Start: a.Start, Date: a.Date, ET0: toUnit(et0)})
+	}
+
+	daily := []ET0Point{}
+	total := 0.0
+	for _, a := range utils.GetDailyAggregates(db, station, start, end) {
+		// Dias parciais subestimam a amplitude térmica e a radiação do dia
+		dayEnd := time.Unix(a.Start, 0).In(utils.Local).AddDate(0, 0, 1).Unix()
+		if a.Start < start || dayEnd-1 > end || dayEnd > now.Unix() {
+			continue
+		}
+		if !a.TempMin.Valid || !a.TempMax.Valid || !a.Humidity.Valid || !a.WindSpeed.Valid || !a.SolarRadiation.Valid {
+			continue
+		}
+		et0 := meteorology.DailyET0(site, meteorology.ETDailyInput{
+			Date:           time.Unix(a.Start, 0).In(utils.Local),
+			TempMin:        a.TempMin.Float64,
+			TempMax:        a.TempMax.Float64,
+			Humidity:       a.Humidity.Float64,
+			WindSpeed:      a.WindSpeed.Float64,
+			SolarRadiation: a.SolarRadiation.Float64,
+		})
+		daily = append(daily, ET0Point{Start: a.Start, Date: a.Date, ET0: toUnit(et0)})
+		total += toUnit(et0)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ET0Response{
+		Start:     start,
+		End:       end,
+		Latitude:  site.Latitude,
+		Elevation: site.Elevation,
+		Hourly:    hourly,
+		Daily:     daily,
+		Total:     total,
+		Unit:      system.Precipitation.Symbol(),
+	})
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// OpenAPIVersion é a versão da API v1 publicada no documento
+const OpenAPIVersion = "1.0.0"
+
+// MarshalOpenAPI serializa o documento OpenAPI 3.0 gerado a partir de V1Routes
+func MarshalOpenAPI() ([]byte, error) {
+	doc, err := json.MarshalIndent(BuildOpenAPI(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar o documento OpenAPI: %w", err)
+	}
+	return doc, nil
+}
+
+// OpenAPIHandler serve o documento OpenAPI já serializado
+func OpenAPIHandler(doc []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(doc)
+	}
+}
+
+// BuildOpenAPI monta o documento OpenAPI a partir da tabela de rotas. Os
+// esquemas vêm dos tipos de resposta por reflexão, então o documento não
+// diverge do que os handlers serializam
+func BuildOpenAPI() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	problem := schemaFor(reflect.TypeOf(Problem{}), schemas)
+	problemResponse := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content": map[string]interface{}{
+				"application/problem+json": map[string]interface{}{"schema": problem},
+			},
+		}
+	}
+
+	paths := map[string]interface{}{}
+	for _, route := range V1Routes {
+		parameters := []interface{}{}
+		for _, p := range route.Params {
+			schema := map[string]interface{}{"type": p.Type}
+			if len(p.Enum) > 0 {
+				schema["enum"] = p.Enum
+			}
+			parameters = append(parameters, map[string]interface{}{
+				"name":        p.Name,
+				"in":          "query",
+				"required":    false,
+				"description": p.Description,
+				"schema":      schema,
+			})
+		}
+
+		content := map[string]interface{}{}
+		if route.Response != nil {
+			content["application/json"] = map[string]interface{}{
+				"schema": schemaFor(reflect.TypeOf(route.Response), schemas),
+			}
+		}
+		for _, contentType := range route.Produces {
+			content[contentType] = map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string"},
+			}
+		}
+
+		responses := map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "OK",
+				"content":     content,
+			},
+			"400":     problemResponse("Parâmetro inválido"),
+			"404":     problemResponse("Recurso não encontrado"),
+			"default": problemResponse("Erro"),
+		}
+		operation := map[string]interface{}{
+			"summary":    route.Summary,
+			"parameters": parameters,
+			"responses":  responses,
+		}
+		if route.Description != "" {
+			operation["description"] = route.Description
+		}
+		if len(route.Consumes) > 0 {
+			body := map[string]interface{}{}
+			for _, contentType := range route.Consumes {
+				body[contentType] = map[string]interface{}{
+					"schema": map[string]interface{}{"type": "string", "format": "binary"},
+				}
+			}
+			operation["requestBody"] = map[string]interface{}{"required": true, "content": body}
+		}
+		if route.Auth {
+			operation["security"] = []interface{}{map[string]interface{}{"bearer": []string{}}}
+			responses["401"] = problemResponse("Token ausente ou inválido")
+		}
+
+		path := strings.TrimPrefix(route.Path, "/api/v1")
+		item, _ := paths[path].(map[string]interface{})
+		if item == nil {
+			item = map[string]interface{}{}
+			paths[path] = item
+		}
+		item[strings.ToLower(route.method())] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Clima PUC API",
+			"version": OpenAPIVersion,
+		},
+		"servers": []interface{}{map[string]interface{}{"url": "/api/v1"}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor retorna o esquema JSON de um tipo Go. Structs nomeadas viram
+// componentes referenciados por $ref; ponteiros são anuláveis
+func schemaFor(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		schema := schemaFor(t.Elem(), schemas)
+		if _, ok := schema["$ref"]; ok {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		nullable := map[string]interface{}{"nullable": true}
+		for k, v := range schema {
+			nullable[k] = v
+		}
+		return nullable
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		name := schemaName(t)
+		if _, ok := schemas[name]; !ok {
+			schemas[name] = nil // evita recursão em tipos autorreferentes
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]interface{}{}
+}
+
+// schemaName usa o pacote como prefixo para tipos de fora de handlers
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	if pkg == "handlers" || pkg == "" {
+		return t.Name()
+	}
+	return strings.ToUpper(pkg[:1]) + pkg[1:] + t.Name()
+}
+
+// structSchema descreve os campos exportados pelos nomes das tags json.
+// Campos sem omitempty são obrigatórios
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaFor(field.Type, schemas)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// Problem é o corpo de erro no formato RFC 7807 (application/problem+json)
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// writeProblem responde com um erro em application/problem+json. Sem um tipo
+// próprio, o título é o texto padrão do status HTTP
+func writeProblem(w http.ResponseWriter, status int, detail string) {
+	if status >= http.StatusInternalServerError {
+		slog.Error(detail, "status", status)
+	} else {
+		slog.Warn(detail, "status", status)
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"projeto/app/summary"
+	"projeto/app/utils"
+	"strconv"
+	"time"
+)
+
+// ApiReportHandler serve os relatórios climatológicos a partir de
+// daily_summary. Com year e month retorna o relatório mensal, só com year o
+// anual, da estação pedida em station (padrão konda). format=txt devolve o
+// relatório em texto no estilo do NOAA
+func ApiReportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	now := time.Now().In(utils.Local)
+
+	year := now.Year()
+	if value := query.Get("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1970 {
+			writeProblem(w, http.StatusBadRequest, "Ano inválido")
+			return
+		}
+		year = parsed
+	}
+
+	month := 0
+	if value := query.Get("month"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 12 {
+			writeProblem(w, http.StatusBadRequest, "Mês inválido")
+			return
+		}
+		month = parsed
+	} else if query.Get("year") == "" {
+		month = int(now.Month())
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	from, to := fmt.Sprintf("%d-01-01", year), fmt.Sprintf("%d-12-31", year)
+	if month > 0 {
+		first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, utils.Local)
+		from, to = first.Format("2006-01-02"), first.AddDate(0, 1, -1).Format("2006-01-02")
+	}
+
+	days, err := summary.LoadRange(db, requestStation(r), from, to)
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar resumos diários")
+		return
+	}
+
+	var report interface{}
+	var text string
+	if month > 0 {
+		monthly := summary.BuildMonthlyReport(year, month, days)
+		report, text = monthly, summary.FormatMonthlyText(monthly)
+	} else {
+		annual := summary.BuildAnnualReport(year, days)
+		report, text = annual, summary.FormatAnnualText(annual)
+	}
+
+	if query.Get("format") == "txt" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(text))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ApiAlmanacHandler lista os recordes de todo o histórico e de cada mês
+func ApiAlmanacHandler(w http.ResponseWriter, r *http.Request) {
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	almanac, err := summary.LoadAlmanac(db, requestStation(r), "")
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao calcular recordes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(almanac)
+}
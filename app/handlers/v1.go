@@ -0,0 +1,758 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"projeto/app/config"
+	"projeto/app/export"
+	"projeto/app/i18n"
+	"projeto/app/ingest"
+	"projeto/app/meteorology"
+	"projeto/app/qc"
+	"projeto/app/stations"
+	"projeto/app/summary"
+	"projeto/app/units"
+	"projeto/app/utils"
+	"strconv"
+	"time"
+)
+
+// MaxHistoryRange limita o período de /api/v1/history
+const MaxHistoryRange = 31 * 24 * time.Hour
+
+// LabeledCode é um código estável acompanhado do texto no idioma pedido
+type LabeledCode struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+}
+
+// UnitLabels são os símbolos das unidades usadas na resposta
+type UnitLabels struct {
+	Temperature   string `json:"temperature"`
+	WindSpeed     string `json:"wind_speed"`
+	Precipitation string `json:"precipitation"`
+}
+
+// BeaufortInfo descreve o grau da escala de Beaufort da leitura
+type BeaufortInfo struct {
+	Number      int     `json:"number"`
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	SeaState    string  `json:"sea_state"`
+	WaveHeightM float64 `json:"wave_height_m"`
+}
+
+// RainTotals são a taxa e os acumulados do pluviômetro
+type RainTotals struct {
+	Rate       float64 `json:"rate"` // por hora
+	LastHour   float64 `json:"last_hour"`
+	Today      float64 `json:"today"`
+	Storm      float64 `json:"storm"`
+	StormStart int64   `json:"storm_start"`
+}
+
+// CurrentConditions é a leitura mais recente com os índices derivados
+type CurrentConditions struct {
+	Station              string               `json:"station"`
+	Timestamp            int64                `json:"timestamp"`
+	DataAgeSeconds       int64                `json:"data_age_seconds"`
+	Stale                bool                 `json:"stale"`
+	Lang                 i18n.Locale          `json:"lang"`
+	Units                UnitLabels           `json:"units"`
+	Temperature          float64              `json:"temperature"`
+	TemperatureStatus    LabeledCode          `json:"temperature_status"`
+	Humidity             float64              `json:"humidity"`
+	HumidityStatus       LabeledCode          `json:"humidity_status"`
+	DewPoint             float64              `json:"dew_point"`
+	HeatIndex            float64              `json:"heat_index"`
+	WindChill            float64              `json:"wind_chill"`
+	ApparentTemperature  float64              `json:"apparent_temperature"`
+	FeelsLike            float64              `json:"feels_like"`
+	UVIndex              float64              `json:"uv_index"`
+	UVStatus             LabeledCode          `json:"uv_status"`
+	RainLevel            float64              `json:"rain_level"`
+	RainStatus           LabeledCode          `json:"rain_status"`
+	Rain                 RainTotals           `json:"rain"`
+	WindSpeed            float64              `json:"wind_speed"`
+	WindSpeedStatus      LabeledCode          `json:"wind_speed_status"`
+	WindDirectionDegrees float64              `json:"wind_direction_degrees"`
+	WindDirection        LabeledCode          `json:"wind_direction"`
+	WindGust             *float64             `json:"wind_gust"`
+	GustFactor           *float64             `json:"gust_factor"`
+	Beaufort             BeaufortInfo         `json:"beaufort"`
+	Quality              map[string][]string  `json:"quality"`
+	Records              []summary.RecordFlag `json:"records"`
+}
+
+// HistoryPoint é uma leitura da série histórica. Valores ausentes ou
+// rejeitados pelo filtro de qualidade são nulos
+type HistoryPoint struct {
+	Timestamp            int64               `json:"timestamp"`
+	Station              string              `json:"station"`
+	Temperature          *float64            `json:"temperature"`
+	Humidity             *float64            `json:"humidity"`
+	RainLevel            *float64            `json:"rain_level"`
+	WindSpeed            *float64            `json:"wind_speed"`
+	WindGust             *float64            `json:"wind_gust"`
+	WindDirectionDegrees *float64            `json:"wind_direction_degrees"`
+	UVIndex              *float64            `json:"uv_index"`
+	SolarRadiation       *float64            `json:"solar_radiation"`
+	DewPoint             *float64            `json:"dew_point"`
+	HeatIndex            *float64            `json:"heat_index"`
+	WindChill            *float64            `json:"wind_chill"`
+	FeelsLike            *float64            `json:"feels_like"`
+	Quality              map[string][]string `json:"quality"`
+}
+
+// HistoryResponse é a resposta de /api/v1/history
+type HistoryResponse struct {
+	Start  int64          `json:"start"`
+	End    int64          `json:"end"`
+	Units  UnitLabels     `json:"units"`
+	Points []HistoryPoint `json:"points"`
+}
+
+// TemperaturePoint é um valor da série de temperatura
+type TemperaturePoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// TemperatureResponse é a resposta de /api/v1/temperature. As estatísticas
+// são nulas quando não há leituras no período
+type TemperatureResponse struct {
+	Start   int64              `json:"start"`
+	End     int64              `json:"end"`
+	Unit    string             `json:"unit"`
+	Points  []TemperaturePoint `json:"points"`
+	Last    *float64           `json:"last"`
+	Average *float64           `json:"average"`
+	Max     *float64           `json:"max"`
+	Min     *float64           `json:"min"`
+}
+
+// APIParam descreve um parâmetro de consulta de uma rota da API v1
+type APIParam struct {
+	Name        string
+	Type        string // "string", "integer", "number" ou "boolean"
+	Description string
+	Enum        []string
+}
+
+// APIRoute descreve uma rota da API v1. A mesma tabela registra os
+// handlers e gera o documento OpenAPI
+type APIRoute struct {
+	Path        string
+	Method      string // padrão GET
+	Summary     string
+	Description string
+	Params      []APIParam
+	Consumes    []string    // Content-Types aceitos no corpo
+	Auth        bool        // exige token Bearer
+	Response    interface{} // valor zero do tipo da resposta JSON
+	Produces    []string    // Content-Types de respostas que não são JSON
+	Handler     http.HandlerFunc
+}
+
+// method retorna o método HTTP da rota
+func (route APIRoute) method() string {
+	if route.Method == "" {
+		return http.MethodGet
+	}
+	return route.Method
+}
+
+var (
+	rangeParams = []APIParam{
+		{Name: "start", Type: "string", Description: "Primeiro dia (AAAA-MM-DD, fuso local)"},
+		{Name: "end", Type: "string", Description: "Último dia, inclusivo (AAAA-MM-DD)"},
+		{Name: "period", Type: "string", Description: "Duração até agora, ex.: 24h, 7d (alternativa a start/end)"},
+	}
+	unitParams = []APIParam{
+		{Name: "units", Type: "string", Description: "Sistema de unidades", Enum: []string{"metric", "imperial"}},
+		{Name: "temp", Type: "string", Description: "Unidade de temperatura", Enum: []string{"c", "f"}},
+		{Name: "wind", Type: "string", Description: "Unidade de velocidade do vento", Enum: []string{"kmh", "ms", "mph", "kn"}},
+		{Name: "rain", Type: "string", Description: "Unidade de precipitação", Enum: []string{"mm", "in"}},
+	}
+	langParam    = APIParam{Name: "lang", Type: "string", Description: "Idioma dos textos (padrão: Accept-Language)", Enum: []string{"pt-BR", "en"}}
+	qualityParam = APIParam{Name: "quality", Type: "string", Description: "Filtro de qualidade dos valores", Enum: []string{"all", "usable", "good"}}
+	stationParam = APIParam{Name: "station", Type: "string", Description: "Estação (padrão: todas)"}
+	// defaultStationParam é a estação das rotas que calculam acumulados ou
+	// resumos, que não podem misturar estações
+	defaultStationParam = APIParam{Name: "station", Type: "string", Description: "Estação (padrão: " + ingest.DefaultStation + ")"}
+)
+
+// requestStation retorna a estação pedida ou a estação padrão
+func requestStation(r *http.Request) string {
+	if station := r.URL.Query().Get("station"); station != "" {
+		return station
+	}
+	return ingest.DefaultStation
+}
+
+// params concatena listas de parâmetros
+func params(groups ...[]APIParam) []APIParam {
+	var all []APIParam
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	return all
+}
+
+// V1Routes são as rotas da API v1, servidas sob /api/v1
+var V1Routes = []APIRoute{
+	{
+		Path:     "/api/v1/current",
+		Summary:  "Condições atuais",
+		Params:   params([]APIParam{stationParam, langParam}, unitParams),
+		Response: CurrentConditions{},
+		Handler:  ApiV1CurrentHandler,
+	},
+	{
+		Path:        "/api/v1/history",
+		Summary:     "Série histórica de leituras",
+		Description: "Período padrão de 24h, limitado a 31 dias.",
+		Params:      params(rangeParams, []APIParam{stationParam, qualityParam}, unitParams),
+		Response:    HistoryResponse{},
+		Handler:     ApiV1HistoryHandler,
+	},
+	{
+		Path:     "/api/v1/temperature",
+		Summary:  "Série e estatísticas de temperatura",
+		Params:   params(rangeParams, []APIParam{defaultStationParam, qualityParam}, unitParams),
+		Response: TemperatureResponse{},
+		Handler:  ApiV1TemperatureHandler,
+	},
+	{
+		Path:    "/api/v1/wind",
+		Summary: "Rosa dos ventos e médias de vento por intervalo",
+		Params: params(rangeParams, []APIParam{
+			{Name: "bucket", Type: "string", Description: "Intervalo de agregação (padrão 1h)"},
+			defaultStationParam,
+			langParam,
+		}, unitParams),
+		Response: WindRoseResponse{},
+		Handler:  ApiWindRoseHandler,
+	},
+	{
+		Path:     "/api/v1/et0",
+		Summary:  "Evapotranspiração de referência (FAO-56)",
+		Params:   params(rangeParams, []APIParam{defaultStationParam}, unitParams),
+		Response: ET0Response{},
+		Handler:  ApiET0Handler,
+	},
+	{
+		Path:    "/api/v1/degree-days",
+		Summary: "Graus-dia e horas de frio",
+		Params: params(rangeParams, []APIParam{
+			{Name: "crop", Type: "string", Description: "Perfil de cultura (padrão milho)"},
+			{Name: "base", Type: "number", Description: "Temperatura base (°C)"},
+			{Name: "cap", Type: "number", Description: "Temperatura de corte (°C)"},
+			defaultStationParam,
+		}),
+		Response: DegreeDaysResponse{},
+		Handler:  ApiDegreeDaysHandler,
+	},
+	{
+		Path:    "/api/v1/reports/monthly",
+		Summary: "Relatório climatológico mensal",
+		Params: []APIParam{
+			{Name: "year", Type: "integer", Description: "Ano (padrão: atual)"},
+			{Name: "month", Type: "integer", Description: "Mês (padrão: atual)"},
+			defaultStationParam,
+		},
+		Response: summary.MonthlyReport{},
+		Handler:  ApiV1MonthlyReportHandler,
+	},
+	{
+		Path:     "/api/v1/reports/annual",
+		Summary:  "Relatório climatológico anual",
+		Params:   []APIParam{{Name: "year", Type: "integer", Description: "Ano (padrão: atual)"}, defaultStationParam},
+		Response: summary.AnnualReport{},
+		Handler:  ApiV1AnnualReportHandler,
+	},
+	{
+		Path:        "/api/v1/export",
+		Summary:     "Exportação de leituras ou agregados em CSV ou NDJSON",
+		Description: "Enviada linha a linha, nas unidades de armazenamento (°C, %, mm, m/s, radianos, W/m²). Nos agregados, rain_level_total é a chuva do intervalo e wind_direction_mean a direção média vetorial.",
+		Params: params(rangeParams, []APIParam{
+			{Name: "format", Type: "string", Description: "Formato (padrão csv)", Enum: []string{export.CSV, export.NDJSON}},
+			{Name: "resolution", Type: "string", Description: "Leituras individuais ou agregados por hora/dia local (padrão raw)", Enum: []string{export.Raw, export.Hour, export.Day}},
+			{Name: "metrics", Type: "string", Description: "Métricas separadas por vírgula (padrão: todas)"},
+			stationParam,
+			qualityParam,
+		}),
+		Produces: []string{export.ContentTypes[export.CSV], export.ContentTypes[export.NDJSON]},
+		Handler:  ApiExportHandler,
+	},
+	{
+		Path:     "/api/v1/almanac",
+		Summary:  "Recordes de todo o histórico e de cada mês",
+		Params:   []APIParam{defaultStationParam},
+		Response: summary.Almanac{},
+		Handler:  ApiAlmanacHandler,
+	},
+	{
+		Path:    "/api/v1/alerts",
+		Summary: "Alertas e regras configuradas",
+		Params: []APIParam{
+			{Name: "state", Type: "string", Description: "Estado (padrão: pendentes e disparados)", Enum: []string{"pending", "firing", "resolved", "all"}},
+			{Name: "limit", Type: "integer", Description: "Quantidade máxima (padrão 100)"},
+		},
+		Response: AlertsResponse{},
+		Handler:  ApiAlertsHandler,
+	},
+	{
+		Path:     "/api/v1/stations",
+		Summary:  "Estações e idade da última leitura",
+		Response: StationsResponse{},
+		Handler:  ApiStationsHandler,
+	},
+	{
+		Path:     "/api/v1/calibrations",
+		Summary:  "Calibrações dos sensores",
+		Response: CalibrationsResponse{},
+		Handler:  ApiCalibrationsHandler,
+	},
+	{
+		Path:        "/api/v1/import",
+		Method:      http.MethodPost,
+		Summary:     "Importação de arquivo histórico CSV ou SenML",
+		Description: "O corpo é o arquivo (ou o campo \"file\" de um formulário multipart). As leituras passam pela calibração e pelo controle de qualidade; as já gravadas para a mesma estação e timestamp são ignoradas. Exige o token de IMPORT_TOKEN.",
+		Params: []APIParam{
+			{Name: "format", Type: "string", Description: "Formato do arquivo (padrão: detectado)", Enum: []string{ingest.FormatAuto, ingest.FormatCSV, ingest.FormatSenML}},
+			{Name: "station", Type: "string", Description: "Estação das leituras sem estação"},
+			{Name: "dry_run", Type: "boolean", Description: "Valida e gera o relatório sem gravar"},
+		},
+		Consumes: []string{"text/csv", "application/senml+json", "application/json", "multipart/form-data"},
+		Auth:     true,
+		Response: ingest.ImportReport{},
+		Handler:  ApiImportHandler,
+	},
+	{
+		Path:        "/api/v1/ingest",
+		Method:      http.MethodPost,
+		Summary:     "Ingestão de leituras SenML por HTTP",
+		Description: "Alternativa ao MQTT para registradores que só fazem POST: o corpo é o mesmo pacote SenML publicado no tópico konda, um array de pacotes ou um pacote por linha. As leituras passam pelo mesmo caminho das mensagens MQTT. Exige o token de um dispositivo cadastrado em INGEST_DEVICES_FILE; dispositivos limitados a algumas estações recebem 403 para as demais. Só um pacote por requisição pode omitir o tempo, e leituras mais de 5 minutos à frente do relógio do servidor são recusadas com 400.",
+		Consumes:    []string{"application/senml+json", "application/json"},
+		Auth:        true,
+		Response:    IngestResponse{},
+		Handler:     ApiIngestHandler,
+	},
+	{
+		Path:     "/api/v1/health",
+		Summary:  "Estado do serviço e da conexão MQTT",
+		Response: HealthResponse{},
+		Handler:  HealthHandler,
+	},
+}
+
+// RegisterV1 registra as rotas da API v1 e o documento OpenAPI. Cada rota
+// aceita só o seu método, e caminhos desconhecidos sob /api/v1/ respondem 404
+// em problem+json. O documento é gerado aqui, para que uma falha impeça a
+// inicialização em vez de aparecer só na primeira requisição
+func RegisterV1(mux *http.ServeMux) error {
+	doc, err := MarshalOpenAPI()
+	if err != nil {
+		return err
+	}
+	for _, route := range V1Routes {
+		mux.HandleFunc(route.Path, allowMethod(route.method(), route.Handler))
+	}
+	mux.HandleFunc("/api/v1/openapi.json", allowMethod(http.MethodGet, OpenAPIHandler(doc)))
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, http.StatusNotFound, "Rota não encontrada: "+r.URL.Path)
+	})
+	return nil
+}
+
+// allowMethod rejeita métodos diferentes do da rota; GET também aceita HEAD
+func allowMethod(method string, next http.HandlerFunc) http.HandlerFunc {
+	allow := method
+	if method == http.MethodGet {
+		allow = "GET, HEAD"
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method && !(method == http.MethodGet && r.Method == http.MethodHead) {
+			w.Header().Set("Allow", allow)
+			writeProblem(w, http.StatusMethodNotAllowed, "Método não permitido: "+r.Method)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// writeJSON serializa a resposta de sucesso
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		slog.Error("Erro ao serializar resposta", "err", err)
+	}
+}
+
+// unitLabels converte o sistema de unidades nos símbolos da resposta
+func unitLabels(system units.System) UnitLabels {
+	return UnitLabels{
+		Temperature:   system.Temperature.Symbol(),
+		WindSpeed:     system.Speed.Symbol(),
+		Precipitation: system.Precipitation.Symbol(),
+	}
+}
+
+// ApiV1CurrentHandler retorna a leitura mais recente com status, índices de
+// conforto, acumulados de chuva, vento e recordes
+func ApiV1CurrentHandler(w http.ResponseWriter, r *http.Request) {
+	system, ok := requestUnits(w, r)
+	if !ok {
+		return
+	}
+	locale := i18n.FromRequest(r)
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	currentData, previousData := utils.GetStationData(db, r.URL.Query().Get("station"))
+	if currentData == nil {
+		writeProblem(w, http.StatusNotFound, "Nenhuma leitura disponível")
+		return
+	}
+
+	station, _ := currentData["station"].(string)
+	timestamp, _ := currentData["timestamp"].(int64)
+	flags, _ := currentData["qc_flags"].(int64)
+	temperature := utils.GetFloatFromMap(currentData, "temperature")
+	humidity := utils.GetFloatFromMap(currentData, "humidity")
+	rainLevel := utils.GetFloatFromMap(currentData, "rain_level")
+	uvIndex := utils.GetFloatFromMap(currentData, "uv_index")
+	windSpeed := utils.GetFloatFromMap(currentData, "average_wind_speed")
+	windKMH := units.MSToKMH(windSpeed)
+	directionCode, _ := utils.RadToDirectionWithIcon(utils.GetFloatFromMap(currentData, "wind_direction"))
+	toTemperature := system.Temperature.FromCelsius
+
+	labeled := func(kind, code string) LabeledCode {
+		return LabeledCode{Code: code, Label: utils.StatusLabel(locale, station, kind, code)}
+	}
+	beaufort := meteorology.BeaufortAt(windSpeed, config.Site().WindHeight)
+
+	now := time.Now()
+	rain := utils.GetRainAccumulation(db, station, now)
+	current := CurrentConditions{
+		Station:              station,
+		Timestamp:            timestamp,
+		DataAgeSeconds:       now.Unix() - timestamp,
+		Stale:                stations.IsStale(timestamp, now),
+		Lang:                 locale,
+		Units:                unitLabels(system),
+		Temperature:          toTemperature(temperature),
+		TemperatureStatus:    labeled("temperature", utils.GetTemperatureStatus(station, temperature)),
+		Humidity:             humidity,
+		HumidityStatus:       labeled("humidity", utils.GetHumidityStatus(station, humidity)),
+		DewPoint:             toTemperature(meteorology.DewPoint(temperature, humidity)),
+		HeatIndex:            toTemperature(meteorology.HeatIndex(temperature, humidity)),
+		WindChill:            toTemperature(meteorology.WindChill(temperature, windKMH)),
+		ApparentTemperature:  toTemperature(meteorology.ApparentTemperature(temperature, humidity, windSpeed)),
+		FeelsLike:            toTemperature(meteorology.FeelsLike(temperature, humidity, windKMH)),
+		UVIndex:              uvIndex,
+		UVStatus:             labeled("uv", utils.GetUVStatus(station, uvIndex)),
+		RainLevel:            system.Precipitation.FromMM(rainLevel),
+		RainStatus:           labeled("rain", utils.GetRainStatus(station, rainLevel, utils.GetFloatFromMap(previousData, "rain_level"))),
+		WindSpeed:            system.Speed.FromMS(windSpeed),
+		WindSpeedStatus:      labeled("wind", utils.GetWindSpeedStatus(station, windKMH)),
+		WindDirectionDegrees: meteorology.NormalizeAngle(utils.GetFloatFromMap(currentData, "wind_direction")) * 180 / math.Pi,
+		WindDirection:        LabeledCode{Code: directionCode, Label: utils.DirectionLabel(locale, directionCode)},
+		Beaufort: BeaufortInfo{
+			Number:      beaufort.Number,
+			Code:        beaufort.Code,
+			Description: locale.T("beaufort." + beaufort.Code),
+			SeaState:    locale.T("beaufort.sea." + beaufort.Code),
+			WaveHeightM: beaufort.WaveHeight,
+		},
+		Rain: RainTotals{
+			Rate:       system.Precipitation.FromMM(rain.Rate),
+			LastHour:   system.Precipitation.FromMM(rain.LastHour),
+			Today:      system.Precipitation.FromMM(rain.Today),
+			Storm:      system.Precipitation.FromMM(rain.Storm),
+			StormStart: rain.StormStart,
+		},
+		Quality: qc.Flags(flags).Names(),
+		Records: []summary.RecordFlag{},
+	}
+	if gust, ok := currentData["wind_gust"].(float64); ok {
+		converted := system.Speed.FromMS(gust)
+		current.WindGust = &converted
+		if factor, ok := meteorology.GustFactor(gust, windSpeed); ok {
+			current.GustFactor = &factor
+		}
+	}
+
+	today := now.In(utils.Local)
+	almanac, err := summary.LoadAlmanac(db, station, today.Format("2006-01-02"))
+	if err != nil {
+		slog.Error("Erro ao calcular recordes", "err", err)
+	} else {
+		current.Records = summary.CheckRecords(almanac, int(today.Month()), summary.CurrentReading{
+			Temperature: temperature,
+			WindGust:    currentGust(currentData),
+			UVIndex:     uvIndex,
+			RainToday:   rain.Today,
+		})
+	}
+
+	writeJSON(w, current)
+}
+
+// currentGust é a rajada da leitura para comparar com o recorde de vento,
+// que guarda rajadas; sem rajada informada vale a velocidade média
+func currentGust(data map[string]interface{}) float64 {
+	speed := utils.GetFloatFromMap(data, "average_wind_speed")
+	if gust, ok := data["wind_gust"].(float64); ok && gust > speed {
+		return gust
+	}
+	return speed
+}
+
+// nullable converte um valor do banco em ponteiro, aplicando a conversão de unidade
+func nullable(value sql.NullFloat64, accept bool, convert func(float64) float64) *float64 {
+	if !value.Valid || !accept {
+		return nil
+	}
+	v := convert(value.Float64)
+	return &v
+}
+
+// ApiV1HistoryHandler retorna as leituras do período, com filtro de
+// qualidade, estação e unidades
+func ApiV1HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start, end, err := utils.ParseRange(query, time.Now(), 24*time.Hour)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if time.Duration(end-start)*time.Second > MaxHistoryRange {
+		writeProblem(w, http.StatusBadRequest, fmt.Sprintf("Período maior que o máximo de %d dias", int(MaxHistoryRange.Hours()/24)))
+		return
+	}
+	quality, err := qc.ParseQuality(query.Get("quality"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	system, ok := requestUnits(w, r)
+	if !ok {
+		return
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	station := query.Get("station")
+	rows, err := db.Query(`
+        SELECT timestamp, station, temperature, humidity, rain_level, average_wind_speed,
+               wind_gust, wind_direction, uv_index, solar_radiation, qc_flags
+        FROM sensor_data
+        WHERE timestamp BETWEEN ? AND ? AND (? = '' OR station = ?)
+        ORDER BY timestamp
+    `, start, end, station, station)
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar dados")
+		return
+	}
+	defer rows.Close()
+
+	identity := func(v float64) float64 { return v }
+	toDegrees := func(v float64) float64 { return meteorology.NormalizeAngle(v) * 180 / math.Pi }
+
+	response := HistoryResponse{Start: start, End: end, Units: unitLabels(system), Points: []HistoryPoint{}}
+	for rows.Next() {
+		var p HistoryPoint
+		var temperature, humidity, rainLevel, windSpeed, windGust, windDirection, uvIndex, radiation sql.NullFloat64
+		var flags qc.Flags
+		if err := rows.Scan(&p.Timestamp, &p.Station, &temperature, &humidity, &rainLevel, &windSpeed,
+			&windGust, &windDirection, &uvIndex, &radiation, &flags); err != nil {
+			slog.Error("Erro ao processar linha", "err", err)
+			continue
+		}
+
+		p.Temperature = nullable(temperature, quality.Accept(flags, qc.Temperature), system.Temperature.FromCelsius)
+		p.Humidity = nullable(humidity, quality.Accept(flags, qc.Humidity), identity)
+		p.RainLevel = nullable(rainLevel, quality.Accept(flags, qc.RainLevel), system.Precipitation.FromMM)
+		p.WindSpeed = nullable(windSpeed, quality.Accept(flags, qc.WindSpeed), system.Speed.FromMS)
+		p.WindGust = nullable(windGust, quality.Accept(flags, qc.WindGust), system.Speed.FromMS)
+		p.WindDirectionDegrees = nullable(windDirection, quality.Accept(flags, qc.WindDirection), toDegrees)
+		p.UVIndex = nullable(uvIndex, quality.Accept(flags, qc.UVIndex), identity)
+		p.SolarRadiation = nullable(radiation, quality.Accept(flags, qc.SolarRadiation), identity)
+		p.Quality = flags.Names()
+
+		// Índices de conforto derivados, quando os valores de base são aceitos
+		if p.Temperature != nil && p.Humidity != nil {
+			t, h := temperature.Float64, humidity.Float64
+			windKMH := units.MSToKMH(windSpeed.Float64)
+			for dest, value := range map[**float64]float64{
+				&p.DewPoint:  meteorology.DewPoint(t, h),
+				&p.HeatIndex: meteorology.HeatIndex(t, h),
+				&p.WindChill: meteorology.WindChill(t, windKMH),
+				&p.FeelsLike: meteorology.FeelsLike(t, h, windKMH),
+			} {
+				converted := system.Temperature.FromCelsius(value)
+				*dest = &converted
+			}
+		}
+		response.Points = append(response.Points, p)
+	}
+
+	writeJSON(w, response)
+}
+
+// ApiV1TemperatureHandler retorna a série de temperatura do período (padrão:
+// dia local atual) com última, média, máxima e mínima
+func ApiV1TemperatureHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	now := time.Now()
+	start, end := utils.TodayRange(now)
+	if query.Get("start") != "" || query.Get("period") != "" {
+		var err error
+		if start, end, err = utils.ParseRange(query, now, 24*time.Hour); err != nil {
+			writeProblem(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if time.Duration(end-start)*time.Second > MaxHistoryRange {
+		writeProblem(w, http.StatusBadRequest, fmt.Sprintf("Período maior que o máximo de %d dias", int(MaxHistoryRange.Hours()/24)))
+		return
+	}
+	quality, err := qc.ParseQuality(query.Get("quality"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	system, ok := requestUnits(w, r)
+	if !ok {
+		return
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+        SELECT timestamp, temperature, qc_flags
+        FROM sensor_data
+        WHERE station = ? AND timestamp BETWEEN ? AND ? AND temperature IS NOT NULL
+        ORDER BY timestamp
+    `, requestStation(r), start, end)
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar dados")
+		return
+	}
+	defer rows.Close()
+
+	response := TemperatureResponse{Start: start, End: end, Unit: system.Temperature.Symbol(), Points: []TemperaturePoint{}}
+	var values []float64
+	for rows.Next() {
+		var p TemperaturePoint
+		var flags qc.Flags
+		if err := rows.Scan(&p.Timestamp, &p.Value, &flags); err != nil {
+			slog.Error("Erro ao ler linha", "err", err)
+			continue
+		}
+		if !quality.Accept(flags, qc.Temperature) {
+			continue
+		}
+		p.Value = system.Temperature.FromCelsius(p.Value)
+		response.Points = append(response.Points, p)
+		values = append(values, p.Value)
+	}
+
+	if len(values) > 0 {
+		last := values[len(values)-1]
+		average := utils.CalculateAverage(values)
+		max := utils.CalculateMax(values)
+		min := utils.CalculateMin(values)
+		response.Last, response.Average, response.Max, response.Min = &last, &average, &max, &min
+	}
+	writeJSON(w, response)
+}
+
+// reportYearMonth lê year e month da consulta, com o ano e mês atuais como padrão
+func reportYearMonth(r *http.Request, withMonth bool) (int, int, error) {
+	now := time.Now().In(utils.Local)
+	year, month := now.Year(), int(now.Month())
+	if value := r.URL.Query().Get("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1970 {
+			return 0, 0, fmt.Errorf("Ano inválido")
+		}
+		year = parsed
+	}
+	if value := r.URL.Query().Get("month"); withMonth && value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 12 {
+			return 0, 0, fmt.Errorf("Mês inválido")
+		}
+		month = parsed
+	}
+	return year, month, nil
+}
+
+// ApiV1MonthlyReportHandler retorna o relatório mensal de daily_summary
+func ApiV1MonthlyReportHandler(w http.ResponseWriter, r *http.Request) {
+	year, month, err := reportYearMonth(r, true)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	first := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, utils.Local)
+	days, err := summary.LoadRange(db, requestStation(r), first.Format("2006-01-02"), first.AddDate(0, 1, -1).Format("2006-01-02"))
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar resumos diários")
+		return
+	}
+	writeJSON(w, summary.BuildMonthlyReport(year, month, days))
+}
+
+// ApiV1AnnualReportHandler retorna o relatório anual de daily_summary
+func ApiV1AnnualReportHandler(w http.ResponseWriter, r *http.Request) {
+	year, _, err := reportYearMonth(r, false)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	days, err := summary.LoadRange(db, requestStation(r), fmt.Sprintf("%d-01-01", year), fmt.Sprintf("%d-12-31", year))
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao buscar resumos diários")
+		return
+	}
+	writeJSON(w, summary.BuildAnnualReport(year, days))
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"projeto/app/i18n"
+	"projeto/app/meteorology"
+	"projeto/app/utils"
+	"time"
+)
+
+// WindPage renderiza a página de vento, que consome /api e /api/windrose
+func WindPage(templates *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		templates.ExecuteTemplate(w, "vento.html", map[string]interface{}{
+			"Lang": i18n.FromRequest(r),
+		})
+	}
+}
+
+// WindRoseResponse é a resposta de /api/windrose e /api/v1/wind
+type WindRoseResponse struct {
+	Start             int64                   `json:"start"`
+	End               int64                   `json:"end"`
+	Rose              meteorology.WindRose    `json:"rose"`
+	Mean              meteorology.WindStats   `json:"mean"`
+	MeanDirection     string                  `json:"mean_direction"`
+	MeanDirectionCode string                  `json:"mean_direction_code"`
+	Buckets           []meteorology.WindStats `json:"buckets"`
+	Unit              string                  `json:"unit"`
+}
+
+// ApiWindRoseHandler retorna a rosa dos ventos (16 setores por faixa de
+// velocidade) e a média vetorial do vento por intervalo no período pedido.
+// Parâmetros: start/end ou period (padrão 24h), bucket (padrão 1h) e as
+// unidades (wind/units), aplicadas às médias. As faixas da rosa seguem em km/h
+func ApiWindRoseHandler(w http.ResponseWriter, r *http.Request) {
+	start, end, err := utils.ParseRange(r.URL.Query(), time.Now(), 24*time.Hour)
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	system, ok := requestUnits(w, r)
+	if !ok {
+		return
+	}
+
+	bucket := time.Hour
+	if bucketParam := r.URL.Query().Get("bucket"); bucketParam != "" {
+		bucket, err = time.ParseDuration(bucketParam)
+		if err != nil || bucket < time.Minute {
+			writeProblem(w, http.StatusBadRequest, "Intervalo de agregação inválido")
+			return
+		}
+	}
+
+	db, err := sql.Open("mysql", DatabaseConfig())
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, "Erro ao conectar ao banco")
+		return
+	}
+	defer db.Close()
+
+	samples := utils.GetWindSamples(db, requestStation(r), start, end)
+	overall := meteorology.AggregateWind(samples)
+	direction, _ := utils.RadToDirectionWithIcon(overall.MeanDirection)
+	buckets := meteorology.BucketWind(samples, int64(bucket.Seconds()))
+
+	// Converter as velocidades médias para a unidade pedida
+	convert := func(stats *meteorology.WindStats) {
+		stats.MeanSpeed = system.Speed.FromMS(stats.MeanSpeed)
+		stats.VectorSpeed = system.Speed.FromMS(stats.VectorSpeed)
+		stats.GustMax = system.Speed.FromMS(stats.GustMax)
+	}
+	convert(&overall)
+	for i := range buckets {
+		convert(&buckets[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(WindRoseResponse{
+		Start:             start,
+		End:               end,
+		Rose:              meteorology.BuildWindRose(samples, meteorology.DefaultSpeedClasses),
+		Mean:              overall,
+		MeanDirection:     utils.DirectionLabel(i18n.FromRequest(r), direction),
+		MeanDirectionCode: direction,
+		Buckets:           buckets,
+		Unit:              system.Speed.Symbol(),
+	})
+}
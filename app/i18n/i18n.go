@@ -0,0 +1,120 @@
+package i18n
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale identifica um idioma do catálogo de mensagens
+type Locale string
+
+const (
+	PortugueseBR Locale = "pt-BR"
+	English      Locale = "en"
+)
+
+// Default é o idioma usado quando o cliente não pede nenhum disponível
+const Default = PortugueseBR
+
+// Locales são os idiomas disponíveis, na ordem de preferência do servidor
+var Locales = []Locale{PortugueseBR, English}
+
+// T traduz uma chave do catálogo. Chaves ausentes no idioma caem para o
+// idioma padrão e, por fim, para a própria chave. Com args, a mensagem é
+// usada como formato de fmt.Sprintf
+func (l Locale) T(key string, args ...interface{}) string {
+	message, ok := catalog[l][key]
+	if !ok {
+		message, ok = catalog[Default][key]
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Has indica se a chave existe no idioma ou no idioma padrão
+func (l Locale) Has(key string) bool {
+	if _, ok := catalog[l][key]; ok {
+		return true
+	}
+	_, ok := catalog[Default][key]
+	return ok
+}
+
+// Match encontra o idioma disponível para uma tag como "en-US" ou "pt"
+func Match(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", false
+	}
+	for _, l := range Locales {
+		if strings.ToLower(string(l)) == tag {
+			return l, true
+		}
+	}
+	// Sem correspondência exata, compara apenas o idioma primário
+	primary := strings.SplitN(tag, "-", 2)[0]
+	for _, l := range Locales {
+		if strings.SplitN(strings.ToLower(string(l)), "-", 2)[0] == primary {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// FromRequest escolhe o idioma pelo parâmetro lang e, na ausência dele,
+// pelo cabeçalho Accept-Language
+func FromRequest(r *http.Request) Locale {
+	if l, ok := Match(r.URL.Query().Get("lang")); ok {
+		return l
+	}
+	return negotiate(r.Header.Get("Accept-Language"))
+}
+
+// negotiate percorre as tags do Accept-Language em ordem de peso (q)
+func negotiate(header string) Locale {
+	type weighted struct {
+		tag    string
+		weight float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		w := weighted{tag: fields[0], weight: 1}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					w.weight = q
+				}
+			}
+		}
+		if w.tag != "" && w.weight > 0 {
+			tags = append(tags, w)
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].weight > tags[j].weight })
+
+	for _, w := range tags {
+		if l, ok := Match(w.tag); ok {
+			return l
+		}
+	}
+	return Default
+}
+
+// FuncMap expõe a tradução aos templates: {{ t .Lang "chave" }}
+var FuncMap = template.FuncMap{
+	"t": func(l Locale, key string, args ...interface{}) string {
+		return l.T(key, args...)
+	},
+}
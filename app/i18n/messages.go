@@ -0,0 +1,251 @@
+package i18n
+
+// catalog guarda as mensagens de cada idioma. As chaves de status seguem o
+// formato "status.<grandeza>.<código>", onde o código é o mesmo devolvido
+// pela API em *_status_code
+var catalog = map[Locale]map[string]string{
+	PortugueseBR: {
+		"status.unknown":               "N/A",
+		"status.temperature.very_cold": "Frio intenso",
+		"status.temperature.cold":      "Clima frio",
+		"status.temperature.pleasant":  "Clima agradável",
+		"status.temperature.hot":       "Clima quente",
+		"status.temperature.very_hot":  "Calor extremo",
+		"status.humidity.dry":          "Ar muito seco",
+		"status.humidity.comfortable":  "Umidade confortável",
+		"status.humidity.humid":        "Ar muito úmido",
+		"status.rain.none":             "Não está chovendo",
+		"status.rain.drizzle":          "Chuviscando",
+		"status.rain.raining":          "Chovendo",
+		"status.uv.low":                "Níveis baixos de UV",
+		"status.uv.moderate":           "Níveis moderados de UV",
+		"status.uv.high":               "Níveis altos de UV",
+		"status.uv.very_high":          "Níveis muito altos de UV",
+		"status.uv.extreme":            "Risco extremo de UV",
+		"status.wind.calm":             "Sem vento",
+		"status.wind.light_breeze":     "Brisa leve",
+		"status.wind.fresh":            "Vento fresco",
+		"status.wind.moderate":         "Vento moderado",
+		"status.wind.strong":           "Vento forte",
+		"status.wind.very_strong":      "Vento muito forte",
+		"status.wind.severe_gale":      "Vendaval severo",
+		"status.wind.storm":            "Tempestade",
+		"status.wind.hurricane":        "Ciclone tropical",
+
+		"direction.unknown": "N/D",
+		"direction.N":       "Norte",
+		"direction.NE":      "Nordeste",
+		"direction.E":       "Leste",
+		"direction.SE":      "Sudeste",
+		"direction.S":       "Sul",
+		"direction.SW":      "Sudoeste",
+		"direction.W":       "Oeste",
+		"direction.NW":      "Noroeste",
+
+		"message.no_data": "Nenhum dado disponível no momento.",
+
+		"beaufort.calm":                "Calmaria",
+		"beaufort.light_air":           "Bafagem",
+		"beaufort.light_breeze":        "Aragem",
+		"beaufort.gentle_breeze":       "Fraco",
+		"beaufort.moderate_breeze":     "Moderado",
+		"beaufort.fresh_breeze":        "Fresco",
+		"beaufort.strong_breeze":       "Muito fresco",
+		"beaufort.near_gale":           "Forte",
+		"beaufort.gale":                "Muito forte",
+		"beaufort.strong_gale":         "Duro",
+		"beaufort.storm":               "Muito duro",
+		"beaufort.violent_storm":       "Tempestuoso",
+		"beaufort.hurricane":           "Furacão",
+		"beaufort.sea.calm":            "Mar espelhado",
+		"beaufort.sea.light_air":       "Pequenas rugas na superfície, sem cristas",
+		"beaufort.sea.light_breeze":    "Ondulações curtas com cristas vítreas, sem arrebentação",
+		"beaufort.sea.gentle_breeze":   "Grandes ondulações, cristas começando a arrebentar",
+		"beaufort.sea.moderate_breeze": "Pequenas vagas com carneiros frequentes",
+		"beaufort.sea.fresh_breeze":    "Vagas moderadas, muitos carneiros e alguns borrifos",
+		"beaufort.sea.strong_breeze":   "Grandes vagas com cristas de espuma branca por toda parte",
+		"beaufort.sea.near_gale":       "Mar grosso, espuma soprada em listras",
+		"beaufort.sea.gale":            "Vagas altas, cristas se desfazendo em borrifos",
+		"beaufort.sea.strong_gale":     "Vagas altas com densas listras de espuma, borrifos reduzem a visibilidade",
+		"beaufort.sea.storm":           "Vagas muito altas, superfície do mar branca de espuma",
+		"beaufort.sea.violent_storm":   "Vagas excepcionalmente altas, mar coberto de espuma",
+		"beaufort.sea.hurricane":       "Ar cheio de espuma e borrifos, visibilidade muito reduzida",
+
+		"wind.title":        "Vento",
+		"wind.gust":         "Rajada",
+		"wind.gust_factor":  "Fator de rajada",
+		"wind.beaufort":     "Escala de Beaufort",
+		"wind.sea_state":    "Estado do mar",
+		"wind.rose":         "Rosa dos ventos (24 h)",
+		"wind.history":      "Vento médio e rajadas (24 h)",
+		"wind.no_gust":      "sem rajada",
+		"index.button.wind": "Conferir dados de vento",
+
+		"page.title":  "Clima PUC",
+		"page.back":   "Voltar",
+		"page.footer": "Desenvolvido por alunos da",
+		"page.rights": "Todos os direitos reservados.",
+
+		"metric.temperature": "Temperatura",
+		"metric.feels_like":  "Sensação Térmica",
+		"metric.dew_point":   "Ponto de orvalho",
+		"metric.humidity":    "Umidade",
+		"metric.rain_level":  "Nível de Chuva",
+		"metric.uv":          "Radiação UV",
+		"metric.wind":        "Direção e Velocidade do Vento",
+		"metric.wind_speed":  "Velocidade do Vento",
+
+		"index.welcome":            "Bem-vindo ao Clima PUC",
+		"index.stale":              "A estação não envia dados há algum tempo.",
+		"index.stale_since":        "Dados desatualizados: última leitura em {time}.",
+		"index.never":              "nunca",
+		"index.button.temperature": "Conferir temperatura atual",
+		"index.button.charts":      "Conferir gráficos de clima",
+		"index.button.irrigation":  "Conferir dados de irrigação",
+
+		"dashboard.title":     "Sensores",
+		"dashboard.chart":     "Dados Climáticos",
+		"dashboard.rain_rate": "Taxa de chuva",
+		"dashboard.last_hour": "Última hora",
+		"dashboard.today":     "Chuva hoje",
+		"dashboard.storm":     "Evento atual",
+
+		"temperature.title":   "Temperaturas",
+		"temperature.current": "Temperatura Atual",
+		"temperature.average": "Temperatura Média",
+		"temperature.max":     "Temperatura Máxima",
+		"temperature.min":     "Temperatura Mínima",
+		"temperature.chart":   "Variação de Temperatura do dia",
+
+		"irrigation.title":         "Irrigação",
+		"irrigation.et0_today":     "ET0 último dia",
+		"irrigation.et0_week":      "ET0 7 dias",
+		"irrigation.daily_average": "Média diária",
+		"irrigation.station":       "Estação",
+		"irrigation.chart":         "Evapotranspiração de Referência (FAO-56)",
+		"irrigation.et0_daily":     "ET0 diária",
+		"irrigation.per_day":       "dia",
+	},
+	English: {
+		"status.unknown":               "N/A",
+		"status.temperature.very_cold": "Very cold",
+		"status.temperature.cold":      "Cold",
+		"status.temperature.pleasant":  "Pleasant",
+		"status.temperature.hot":       "Hot",
+		"status.temperature.very_hot":  "Extreme heat",
+		"status.humidity.dry":          "Very dry air",
+		"status.humidity.comfortable":  "Comfortable humidity",
+		"status.humidity.humid":        "Very humid air",
+		"status.rain.none":             "Not raining",
+		"status.rain.drizzle":          "Drizzling",
+		"status.rain.raining":          "Raining",
+		"status.uv.low":                "Low UV levels",
+		"status.uv.moderate":           "Moderate UV levels",
+		"status.uv.high":               "High UV levels",
+		"status.uv.very_high":          "Very high UV levels",
+		"status.uv.extreme":            "Extreme UV risk",
+		"status.wind.calm":             "Calm",
+		"status.wind.light_breeze":     "Light breeze",
+		"status.wind.fresh":            "Fresh breeze",
+		"status.wind.moderate":         "Moderate wind",
+		"status.wind.strong":           "Strong wind",
+		"status.wind.very_strong":      "Very strong wind",
+		"status.wind.severe_gale":      "Severe gale",
+		"status.wind.storm":            "Storm",
+		"status.wind.hurricane":        "Tropical cyclone",
+
+		"direction.unknown": "N/A",
+		"direction.N":       "North",
+		"direction.NE":      "Northeast",
+		"direction.E":       "East",
+		"direction.SE":      "Southeast",
+		"direction.S":       "South",
+		"direction.SW":      "Southwest",
+		"direction.W":       "West",
+		"direction.NW":      "Northwest",
+
+		"message.no_data": "No data available at the moment.",
+
+		"beaufort.calm":                "Calm",
+		"beaufort.light_air":           "Light air",
+		"beaufort.light_breeze":        "Light breeze",
+		"beaufort.gentle_breeze":       "Gentle breeze",
+		"beaufort.moderate_breeze":     "Moderate breeze",
+		"beaufort.fresh_breeze":        "Fresh breeze",
+		"beaufort.strong_breeze":       "Strong breeze",
+		"beaufort.near_gale":           "Near gale",
+		"beaufort.gale":                "Gale",
+		"beaufort.strong_gale":         "Strong gale",
+		"beaufort.storm":               "Storm",
+		"beaufort.violent_storm":       "Violent storm",
+		"beaufort.hurricane":           "Hurricane force",
+		"beaufort.sea.calm":            "Sea like a mirror",
+		"beaufort.sea.light_air":       "Ripples without crests",
+		"beaufort.sea.light_breeze":    "Small wavelets with glassy crests, not breaking",
+		"beaufort.sea.gentle_breeze":   "Large wavelets, crests begin to break",
+		"beaufort.sea.moderate_breeze": "Small waves with frequent white horses",
+		"beaufort.sea.fresh_breeze":    "Moderate waves, many white horses and some spray",
+		"beaufort.sea.strong_breeze":   "Large waves with white foam crests everywhere",
+		"beaufort.sea.near_gale":       "Sea heaps up, foam blown in streaks",
+		"beaufort.sea.gale":            "Moderately high waves, crests break into spindrift",
+		"beaufort.sea.strong_gale":     "High waves with dense foam streaks, spray reduces visibility",
+		"beaufort.sea.storm":           "Very high waves, sea surface white with foam",
+		"beaufort.sea.violent_storm":   "Exceptionally high waves, sea covered with foam",
+		"beaufort.sea.hurricane":       "Air filled with foam and spray, visibility very poor",
+
+		"wind.title":        "Wind",
+		"wind.gust":         "Gust",
+		"wind.gust_factor":  "Gust factor",
+		"wind.beaufort":     "Beaufort scale",
+		"wind.sea_state":    "Sea state",
+		"wind.rose":         "Wind rose (24 h)",
+		"wind.history":      "Mean wind and gusts (24 h)",
+		"wind.no_gust":      "no gust",
+		"index.button.wind": "View wind data",
+
+		"page.title":  "Clima PUC",
+		"page.back":   "Back",
+		"page.footer": "Developed by students of",
+		"page.rights": "All rights reserved.",
+
+		"metric.temperature": "Temperature",
+		"metric.feels_like":  "Feels Like",
+		"metric.dew_point":   "Dew point",
+		"metric.humidity":    "Humidity",
+		"metric.rain_level":  "Rain Level",
+		"metric.uv":          "UV Radiation",
+		"metric.wind":        "Wind Direction and Speed",
+		"metric.wind_speed":  "Wind Speed",
+
+		"index.welcome":            "Welcome to Clima PUC",
+		"index.stale":              "The station has not sent data for a while.",
+		"index.stale_since":        "Stale data: last reading at {time}.",
+		"index.never":              "never",
+		"index.button.temperature": "Check current temperature",
+		"index.button.charts":      "View weather charts",
+		"index.button.irrigation":  "View irrigation data",
+
+		"dashboard.title":     "Sensors",
+		"dashboard.chart":     "Weather Data",
+		"dashboard.rain_rate": "Rain rate",
+		"dashboard.last_hour": "Last hour",
+		"dashboard.today":     "Rain today",
+		"dashboard.storm":     "Current event",
+
+		"temperature.title":   "Temperatures",
+		"temperature.current": "Current Temperature",
+		"temperature.average": "Average Temperature",
+		"temperature.max":     "Maximum Temperature",
+		"temperature.min":     "Minimum Temperature",
+		"temperature.chart":   "Today's Temperature Variation",
+
+		"irrigation.title":         "Irrigation",
+		"irrigation.et0_today":     "ET0 last day",
+		"irrigation.et0_week":      "ET0 7 days",
+		"irrigation.daily_average": "Daily average",
+		"irrigation.station":       "Station",
+		"irrigation.chart":         "Reference Evapotranspiration (FAO-56)",
+		"irrigation.et0_daily":     "Daily ET0",
+		"irrigation.per_day":       "day",
+	},
+}
@@ -0,0 +1,179 @@
+package ingest
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"projeto/app/qc"
+	"projeto/app/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts são os formatos aceitos na coluna "time", no fuso local quando
+// não trazem o deslocamento
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+}
+
+// RowError é uma linha do CSV, ou um pacote do SenML, que não pôde ser lida
+type RowError struct {
+	Line int    `json:"line"`
+	Err  string `json:"error"`
+}
+
+// CSVResult é o resultado da leitura de um CSV
+type CSVResult struct {
+	Readings []Reading
+	Errors   []RowError
+	Ignored  []string // colunas do cabeçalho que não são métricas
+}
+
+// ReadCSV lê um CSV com cabeçalho. O instante vem de "timestamp" (UNIX, em
+// segundos ou milissegundos) ou de "time" (ISO 8601 ou data e hora locais);
+// "station" é opcional. As métricas usam os nomes das colunas de sensor_data
+// ou os nomes SenML; células vazias são valores ausentes. O formato é o
+// mesmo da exportação em resolução raw, então exportações podem ser reimportadas
+func ReadCSV(r io.Reader) (CSVResult, error) {
+	var result CSVResult
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return result, fmt.Errorf("arquivo CSV vazio")
+	} else if err != nil {
+		return result, fmt.Errorf("cabeçalho CSV inválido: %w", err)
+	}
+	if len(header) == 1 && strings.Contains(header[0], ";") {
+		return result, fmt.Errorf("separador ';' não suportado, use vírgulas")
+	}
+
+	timestampCol, timeCol, stationCol := -1, -1, -1
+	metrics := map[int]string{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch {
+		case name == "timestamp":
+			timestampCol = i
+		case name == "time" || name == "datetime":
+			timeCol = i
+		case name == "station":
+			stationCol = i
+		case SenMLNames[name] != "":
+			metrics[i] = SenMLNames[name]
+		case isMetric(name):
+			metrics[i] = name
+		default:
+			result.Ignored = append(result.Ignored, name)
+		}
+	}
+	if timestampCol < 0 && timeCol < 0 {
+		return result, fmt.Errorf("o cabeçalho precisa de uma coluna timestamp ou time")
+	}
+	if len(metrics) == 0 {
+		return result, fmt.Errorf("nenhuma coluna de métrica reconhecida no cabeçalho")
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.Errors = append(result.Errors, RowError{Line: parseErr.Line, Err: parseErr.Err.Error()})
+			continue
+		} else if err != nil {
+			return result, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		reading, err := parseRow(record, timestampCol, timeCol, stationCol, metrics)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Line: line, Err: err.Error()})
+			continue
+		}
+		result.Readings = append(result.Readings, reading)
+	}
+
+	sort.SliceStable(result.Readings, func(i, j int) bool {
+		return result.Readings[i].Timestamp < result.Readings[j].Timestamp
+	})
+	return result, nil
+}
+
+// parseRow converte uma linha do CSV em leitura
+func parseRow(record []string, timestampCol, timeCol, stationCol int, metrics map[int]string) (Reading, error) {
+	cell := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	reading := Reading{Station: cell(stationCol), Values: map[string]float64{}}
+	if value := cell(timestampCol); value != "" {
+		ts, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return reading, fmt.Errorf("timestamp inválido: %s", value)
+		}
+		if ts > 1e12 {
+			ts /= 1000
+		}
+		reading.Timestamp = ts
+	} else if value := cell(timeCol); value != "" {
+		t, err := parseTime(value)
+		if err != nil {
+			return reading, err
+		}
+		reading.Timestamp = t.Unix()
+	} else {
+		return reading, fmt.Errorf("linha sem data e hora")
+	}
+
+	for i, metric := range metrics {
+		value := cell(i)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			return reading, fmt.Errorf("valor inválido para %s: %s", metric, value)
+		}
+		reading.Values[metric] = parsed
+	}
+	if len(reading.Values) == 0 {
+		return reading, fmt.Errorf("linha sem nenhum valor")
+	}
+	return reading, nil
+}
+
+// parseTime interpreta a data e hora nos formatos de timeLayouts
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, utils.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("data e hora inválidas: %s", value)
+}
+
+// isMetric indica se o nome é uma coluna de medida de sensor_data
+func isMetric(name string) bool {
+	for _, metric := range qc.Metrics {
+		if metric == name {
+			return true
+		}
+	}
+	return false
+}
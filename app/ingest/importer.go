@@ -0,0 +1,273 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"projeto/app/qc"
+	"projeto/app/summary"
+	"projeto/app/utils"
+	"sort"
+	"time"
+)
+
+// Formatos de arquivo aceitos na importação
+const (
+	FormatAuto  = "auto"
+	FormatCSV   = "csv"
+	FormatSenML = "senml"
+)
+
+// ErrInvalidFile indica um arquivo que não pôde ser lido no formato pedido
+var ErrInvalidFile = errors.New("arquivo inválido")
+
+// maxReportErrors limita as linhas com erro listadas no relatório
+const maxReportErrors = 50
+
+// ImportOptions controla uma importação
+type ImportOptions struct {
+	Format  string // FormatAuto detecta pelo primeiro caractere do arquivo
+	Station string // estação das leituras que não informam uma (padrão DefaultStation)
+	DryRun  bool   // valida, calibra e verifica sem gravar
+}
+
+// ImportReport resume uma importação
+type ImportReport struct {
+	Format         string         `json:"format"`
+	DryRun         bool           `json:"dry_run"`
+	Readings       int            `json:"readings"`          // leituras lidas do arquivo
+	Invalid        int            `json:"invalid"`           // linhas ou registros descartados
+	Errors         []RowError     `json:"errors"`            // primeiras linhas com erro
+	IgnoredColumns []string       `json:"ignored_columns"`   // colunas CSV desconhecidas
+	DuplicatesFile int            `json:"duplicates_file"`   // repetidas no próprio arquivo
+	Existing       int            `json:"duplicates_stored"` // já presentes em sensor_data
+	Imported       int            `json:"imported"`          // gravadas (ou que seriam, em dry-run)
+	Failed         int            `json:"failed"`            // erros ao gravar
+	Flagged        int            `json:"flagged"`           // com algum indicador de qualidade
+	FlagCounts     map[string]int `json:"flag_counts"`       // indicadores por métrica
+	Stations       []string       `json:"stations"`
+	From           int64          `json:"from,omitempty"`
+	To             int64          `json:"to,omitempty"`
+	DaysUpdated    int            `json:"days_updated"` // resumos diários reconstruídos
+}
+
+// DetectFormat identifica SenML (JSON) ou CSV pelo primeiro caractere
+func DetectFormat(r *bufio.Reader) string {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return FormatCSV
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.ReadByte()
+			continue
+		case '[':
+			return FormatSenML
+		}
+		return FormatCSV
+	}
+}
+
+// Import lê o arquivo e grava as leituras pelo mesmo caminho do MQTT:
+// calibração, controle de qualidade e sensor_data. Leituras repetidas no
+// arquivo ou já presentes no banco (mesma estação e timestamp) são ignoradas.
+// Os alertas não são avaliados para dados históricos; ao final os resumos
+// diários do período são reconstruídos e as estações registradas
+func Import(db *sql.DB, r io.Reader, opts ImportOptions) (ImportReport, error) {
+	report := ImportReport{
+		Format:     opts.Format,
+		DryRun:     opts.DryRun,
+		Errors:     []RowError{},
+		FlagCounts: map[string]int{},
+		Stations:   []string{},
+	}
+	buffered := bufio.NewReader(r)
+	if report.Format == "" || report.Format == FormatAuto {
+		report.Format = DetectFormat(buffered)
+	}
+
+	var readings []Reading
+	switch report.Format {
+	case FormatCSV:
+		result, err := ReadCSV(buffered)
+		if err != nil {
+			return report, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+		readings = result.Readings
+		report.Invalid = len(result.Errors)
+		report.IgnoredColumns = result.Ignored
+		if len(result.Errors) > maxReportErrors {
+			result.Errors = result.Errors[:maxReportErrors]
+		}
+		report.Errors = append(report.Errors, result.Errors...)
+	case FormatSenML:
+		// Dados históricos não têm instante de recebimento: pacotes sem
+		// tempo absoluto viram erros em vez de receber a hora atual
+		result, err := ReadSenML(buffered, 0)
+		if err != nil {
+			return report, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+		readings = result.Readings
+		report.Invalid = result.Skipped + len(result.Errors)
+		if len(result.Errors) > maxReportErrors {
+			result.Errors = result.Errors[:maxReportErrors]
+		}
+		report.Errors = append(report.Errors, result.Errors...)
+	default:
+		return report, fmt.Errorf("%w: formato desconhecido: %s", ErrInvalidFile, report.Format)
+	}
+	report.Readings = len(readings)
+
+	station := opts.Station
+	if station == "" {
+		station = DefaultStation
+	}
+	for i := range readings {
+		if readings[i].Station == "" {
+			readings[i].Station = station
+		}
+	}
+
+	// Ordem por estação e tempo, necessária para o controle de qualidade
+	sort.SliceStable(readings, func(i, j int) bool {
+		if readings[i].Station != readings[j].Station {
+			return readings[i].Station < readings[j].Station
+		}
+		return readings[i].Timestamp < readings[j].Timestamp
+	})
+
+	pipeline := &Pipeline{DB: db, Checker: qc.NewChecker(qc.DefaultLimits), DryRun: opts.DryRun}
+	var stored map[int64]bool
+	var last Reading
+	for i, reading := range readings {
+		if i > 0 && reading.Station == last.Station && reading.Timestamp == last.Timestamp {
+			report.DuplicatesFile++
+			continue
+		}
+		last = reading
+
+		if i == 0 || reading.Station != readings[i-1].Station {
+			var err error
+			if stored, err = storedTimestamps(db, readings, i); err != nil {
+				return report, err
+			}
+			report.Stations = append(report.Stations, reading.Station)
+		}
+		if stored[reading.Timestamp] {
+			report.Existing++
+			continue
+		}
+
+		flags, _, err := pipeline.Process(reading)
+		if err != nil {
+			report.Failed++
+			slog.Error("Erro ao importar leitura", "station", reading.Station, "timestamp", reading.Timestamp, "err", err)
+			continue
+		}
+		report.Imported++
+		if flags != 0 {
+			report.Flagged++
+			for metric := range flags.Names() {
+				report.FlagCounts[metric]++
+			}
+		}
+		if report.From == 0 || reading.Timestamp < report.From {
+			report.From = reading.Timestamp
+		}
+		if reading.Timestamp > report.To {
+			report.To = reading.Timestamp
+		}
+	}
+
+	if opts.DryRun || report.Imported == 0 {
+		return report, nil
+	}
+	// Importações não atualizam a tabela stations: só a ingestão em tempo
+	// real indica que a estação está no ar e deve ser vigiada
+	for _, station := range report.Stations {
+		days, err := summary.Backfill(db, station, time.Unix(report.From, 0), time.Unix(report.To, 0))
+		report.DaysUpdated += days
+		if err != nil {
+			return report, fmt.Errorf("erro ao atualizar resumos diários: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// storedTimestamps retorna os timestamps já gravados da estação de
+// readings[start] no intervalo coberto pelas suas leituras
+func storedTimestamps(db *sql.DB, readings []Reading, start int) (map[int64]bool, error) {
+	station := readings[start].Station
+	from, to := readings[start].Timestamp, readings[start].Timestamp
+	for _, r := range readings[start:] {
+		if r.Station != station {
+			break
+		}
+		to = r.Timestamp
+	}
+
+	rows, err := db.Query("SELECT timestamp FROM sensor_data WHERE station = ? AND timestamp BETWEEN ? AND ?",
+		station, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar leituras existentes: %w", err)
+	}
+	defer rows.Close()
+
+	stored := map[int64]bool{}
+	for rows.Next() {
+		var timestamp int64
+		if err := rows.Scan(&timestamp); err != nil {
+			return nil, err
+		}
+		stored[timestamp] = true
+	}
+	return stored, rows.Err()
+}
+
+// FormatReport descreve o relatório em texto para o terminal
+func FormatReport(r ImportReport) string {
+	var b bytes.Buffer
+	mode := "importação"
+	if r.DryRun {
+		mode = "simulação (dry-run), nada foi gravado"
+	}
+	fmt.Fprintf(&b, "Formato: %s — %s\n", r.Format, mode)
+	fmt.Fprintf(&b, "Leituras no arquivo:      %d\n", r.Readings)
+	fmt.Fprintf(&b, "Linhas inválidas:         %d\n", r.Invalid)
+	fmt.Fprintf(&b, "Repetidas no arquivo:     %d\n", r.DuplicatesFile)
+	fmt.Fprintf(&b, "Já existentes no banco:   %d\n", r.Existing)
+	fmt.Fprintf(&b, "Importadas:               %d\n", r.Imported)
+	if r.Failed > 0 {
+		fmt.Fprintf(&b, "Falhas ao gravar:         %d\n", r.Failed)
+	}
+	fmt.Fprintf(&b, "Com indicadores de QC:    %d\n", r.Flagged)
+	metrics := make([]string, 0, len(r.FlagCounts))
+	for metric := range r.FlagCounts {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	for _, metric := range metrics {
+		fmt.Fprintf(&b, "  %-20s %d\n", metric, r.FlagCounts[metric])
+	}
+	if len(r.Stations) > 0 {
+		fmt.Fprintf(&b, "Estações: %v\n", r.Stations)
+	}
+	if r.Imported > 0 {
+		fmt.Fprintf(&b, "Período: %s a %s\n", time.Unix(r.From, 0).In(utils.Local).Format(time.RFC3339), time.Unix(r.To, 0).In(utils.Local).Format(time.RFC3339))
+	}
+	if r.DaysUpdated > 0 {
+		fmt.Fprintf(&b, "Resumos diários reconstruídos: %d\n", r.DaysUpdated)
+	}
+	if len(r.IgnoredColumns) > 0 {
+		fmt.Fprintf(&b, "Colunas ignoradas: %v\n", r.IgnoredColumns)
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(&b, "  linha %d: %s\n", e.Line, e.Err)
+	}
+	return b.String()
+}
@@ -0,0 +1,229 @@
+// Package ingest concentra o caminho de gravação das leituras: calibração,
+// controle de qualidade, sensor_data e, para dados ao vivo, resumo diário e
+// alertas. MQTT, importação e as demais entradas passam por aqui
+package ingest
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"projeto/app/alerts"
+	"projeto/app/calibration"
+	"projeto/app/config"
+	"projeto/app/meteorology"
+	"projeto/app/qc"
+	"projeto/app/stations"
+	"projeto/app/summary"
+	"projeto/app/units"
+	"projeto/app/utils"
+	"strings"
+	"time"
+)
+
+// DefaultStation é a estação das leituras que não informam o nome base
+const DefaultStation = "konda"
+
+// Reading é uma leitura recebida, com os valores pelos nomes das colunas de
+// sensor_data. Métricas ausentes de Values são gravadas como NULL
+type Reading struct {
+	Station   string
+	Timestamp int64
+	Values    map[string]float64
+}
+
+// SensorData representa os dados do sensor
+type SensorData struct {
+	RainLevel        float64  `json:"rain_level"`
+	AverageWindSpeed float64  `json:"average_wind_speed"`
+	WindDirection    float64  `json:"wind_direction"`
+	Humidity         float64  `json:"humidity"`
+	UVIndex          float64  `json:"uv_index"`
+	SolarRadiation   float64  `json:"solar_radiation"`
+	WindGust         *float64 `json:"wind_gust,omitempty"` // rajada (m/s), quando a estação envia
+	Temperature      float64  `json:"temperature"`
+	Timestamp        int64    `json:"timestamp"`
+	Station          string   `json:"station"`
+	QCFlags          qc.Flags `json:"qc_flags"`
+}
+
+// qcDependents relaciona cada valor medido às métricas de alerta calculadas a partir dele
+var qcDependents = map[string][]string{
+	qc.Temperature:    {"temperature", "dew_point", "heat_index", "wind_chill", "feels_like"},
+	qc.Humidity:       {"humidity", "dew_point", "heat_index", "feels_like"},
+	qc.RainLevel:      {"rain_level"},
+	qc.WindSpeed:      {"wind_speed", "wind_speed_kmh", "wind_chill", "feels_like", "beaufort", "gust_factor"},
+	qc.WindDirection:  {"wind_direction"},
+	qc.UVIndex:        {"uv_index"},
+	qc.SolarRadiation: {"solar_radiation"},
+	qc.WindGust:       {"wind_gust", "wind_gust_kmh", "gust_factor"},
+}
+
+// Values retorna os valores medidos pelos nomes das colunas de sensor_data.
+// A rajada só é incluída quando informada
+func (d SensorData) Values() map[string]float64 {
+	values := map[string]float64{
+		qc.Temperature:    d.Temperature,
+		qc.Humidity:       d.Humidity,
+		qc.RainLevel:      d.RainLevel,
+		qc.WindSpeed:      d.AverageWindSpeed,
+		qc.WindDirection:  d.WindDirection,
+		qc.UVIndex:        d.UVIndex,
+		qc.SolarRadiation: d.SolarRadiation,
+	}
+	if d.WindGust != nil {
+		values[qc.WindGust] = *d.WindGust
+	}
+	return values
+}
+
+// Metrics retorna os valores da leitura, incluindo os índices derivados,
+// pelos nomes usados nas regras de alerta
+func (d SensorData) Metrics() map[string]float64 {
+	windKMH := units.MSToKMH(d.AverageWindSpeed)
+	metrics := map[string]float64{
+		"rain_level":      d.RainLevel,
+		"wind_speed":      d.AverageWindSpeed,
+		"wind_speed_kmh":  windKMH,
+		"wind_direction":  d.WindDirection,
+		"humidity":        d.Humidity,
+		"uv_index":        d.UVIndex,
+		"solar_radiation": d.SolarRadiation,
+		"temperature":     d.Temperature,
+		"dew_point":       meteorology.DewPoint(d.Temperature, d.Humidity),
+		"heat_index":      meteorology.HeatIndex(d.Temperature, d.Humidity),
+		"wind_chill":      meteorology.WindChill(d.Temperature, windKMH),
+		"feels_like":      meteorology.FeelsLike(d.Temperature, d.Humidity, windKMH),
+		"beaufort":        float64(meteorology.BeaufortAt(d.AverageWindSpeed, config.Site().WindHeight).Number),
+	}
+	if d.WindGust != nil {
+		metrics["wind_gust"] = *d.WindGust
+		metrics["wind_gust_kmh"] = units.MSToKMH(*d.WindGust)
+		if factor, ok := meteorology.GustFactor(*d.WindGust, d.AverageWindSpeed); ok {
+			metrics["gust_factor"] = factor
+		}
+	}
+	return metrics
+}
+
+// AlertMetrics lista todas as métricas que a ingestão entrega às regras de
+// alerta: as da leitura, os acumulados de chuva e a idade dos dados
+func AlertMetrics() []string {
+	gust := 2 * meteorology.GustFactorMinSpeed
+	sample := SensorData{AverageWindSpeed: meteorology.GustFactorMinSpeed, WindGust: &gust}
+	var names []string
+	for name := range sample.Metrics() {
+		names = append(names, name)
+	}
+	return append(names, "rain_rate", "rain_last_hour", "rain_today", stations.AgeMetric)
+}
+
+// SetValues atualiza os valores medidos a partir dos nomes das colunas
+func (d *SensorData) SetValues(values map[string]float64) {
+	d.Temperature = values[qc.Temperature]
+	d.Humidity = values[qc.Humidity]
+	d.RainLevel = values[qc.RainLevel]
+	d.AverageWindSpeed = values[qc.WindSpeed]
+	d.WindDirection = values[qc.WindDirection]
+	d.UVIndex = values[qc.UVIndex]
+	d.SolarRadiation = values[qc.SolarRadiation]
+	d.WindGust = nil
+	if gust, ok := values[qc.WindGust]; ok {
+		d.WindGust = &gust
+	}
+}
+
+// Pipeline grava leituras em sensor_data. Live indica dados em tempo real:
+// cada leitura atualiza a estação, o resumo diário e avalia os alertas.
+// Cargas históricas deixam essas etapas para o fim (ver Import)
+type Pipeline struct {
+	DB      *sql.DB
+	Checker *qc.Checker // nil usa o verificador padrão, compartilhado com o MQTT
+	Live    bool
+	DryRun  bool // calibra e verifica sem gravar nada
+}
+
+// Process calibra, verifica a qualidade e grava a leitura, retornando os
+// indicadores de qualidade e os valores calibrados
+func (p *Pipeline) Process(r Reading) (qc.Flags, map[string]float64, error) {
+	// Calibração por estação e métrica; os valores brutos são preservados
+	values := calibration.Apply(p.DB, r.Station, r.Timestamp, r.Values)
+	if !p.DryRun {
+		if err := calibration.SaveRaw(p.DB, r.Station, r.Timestamp, r.Values); err != nil {
+			slog.Error("Erro ao salvar valores brutos", "station", r.Station, "timestamp", r.Timestamp, "err", err)
+		}
+	}
+
+	// Controle de qualidade: os valores suspeitos são gravados com indicadores
+	var flags qc.Flags
+	if p.Checker != nil {
+		flags = p.Checker.Check(r.Station, r.Timestamp, values)
+	} else {
+		flags = qc.Check(r.Station, r.Timestamp, values)
+	}
+	if flags != 0 && p.Live {
+		slog.Warn("Leitura com indicadores de qualidade", "station", r.Station,
+			"timestamp", r.Timestamp, "flags", flags.Names())
+	}
+	if p.DryRun {
+		return flags, values, nil
+	}
+
+	args := make([]interface{}, 0, len(qc.Metrics)+3)
+	updates := make([]string, len(qc.Metrics))
+	for i, metric := range qc.Metrics {
+		if value, ok := values[metric]; ok {
+			args = append(args, value)
+		} else {
+			args = append(args, nil)
+		}
+		updates[i] = fmt.Sprintf("%s=VALUES(%s)", metric, metric)
+	}
+	args = append(args, r.Timestamp, flags, r.Station)
+	_, err := p.DB.Exec(fmt.Sprintf(`
+		INSERT INTO sensor_data (%s, timestamp, qc_flags, station)
+		VALUES (%s?, ?, ?)
+		ON DUPLICATE KEY UPDATE %s, qc_flags=VALUES(qc_flags)
+	`, strings.Join(qc.Metrics, ", "), strings.Repeat("?, ", len(qc.Metrics)), strings.Join(updates, ", ")), args...)
+	if err != nil {
+		return flags, values, fmt.Errorf("erro ao salvar leitura %d: %w", r.Timestamp, err)
+	}
+	slog.Debug("Dados salvos no MySQL", "station", r.Station, "timestamp", r.Timestamp, "values", values)
+
+	if p.Live {
+		p.afterSave(r, values, flags)
+	}
+	return flags, values, nil
+}
+
+// afterSave atualiza a estação e o resumo diário e avalia os alertas
+func (p *Pipeline) afterSave(r Reading, values map[string]float64, flags qc.Flags) {
+	if err := stations.Touch(p.DB, r.Station, r.Timestamp); err != nil {
+		slog.Error("Erro ao atualizar estação", "station", r.Station, "err", err)
+	}
+
+	// Mantém o resumo diário em dia com a nova leitura
+	if err := summary.AddReading(p.DB, r.Station, r.Timestamp, values, flags); err != nil {
+		slog.Error("Erro ao atualizar resumo diário", "station", r.Station, "timestamp", r.Timestamp, "err", err)
+	}
+
+	// Avalia as regras de alerta com a leitura e os acumulados de chuva,
+	// ignorando as métricas que dependem de valores ausentes ou fora dos
+	// limites físicos
+	data := SensorData{Station: r.Station, Timestamp: r.Timestamp, QCFlags: flags}
+	data.SetValues(values)
+	metrics := data.Metrics()
+	for metric, dependents := range qcDependents {
+		_, present := values[metric]
+		if !present || flags.Get(metric)&qc.FlagRange != 0 {
+			for _, name := range dependents {
+				delete(metrics, name)
+			}
+		}
+	}
+	rain := utils.GetRainAccumulation(p.DB, r.Station, time.Unix(r.Timestamp, 0))
+	metrics["rain_rate"] = rain.Rate
+	metrics["rain_last_hour"] = rain.LastHour
+	metrics["rain_today"] = rain.Today
+	metrics[stations.AgeMetric] = 0
+	alerts.Default().Evaluate(p.DB, r.Station, r.Timestamp, metrics)
+}
@@ -0,0 +1,161 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"projeto/app/qc"
+	"sort"
+)
+
+// SenMLNames associa os nomes SenML enviados pela estação às colunas de sensor_data
+var SenMLNames = map[string]string{
+	"emw_temperature":        qc.Temperature,
+	"emw_humidity":           qc.Humidity,
+	"emw_rain_level":         qc.RainLevel,
+	"emw_average_wind_speed": qc.WindSpeed,
+	"emw_wind_direction":     qc.WindDirection,
+	"emw_uv":                 qc.UVIndex,
+	"emw_solar_radiation":    qc.SolarRadiation,
+	"emw_wind_gust":          qc.WindGust,
+	"emw_max_wind_speed":     qc.WindGust,
+}
+
+// relativeTimeLimit é o limite da RFC 8428 abaixo do qual o tempo é relativo
+// ao instante de recebimento
+const relativeTimeLimit = 1 << 28
+
+// senmlRecord é um registro SenML. Os campos são lidos sem tipo para que
+// registros malformados sejam ignorados individualmente
+type senmlRecord map[string]interface{}
+
+// DecodeSenML converte um pacote SenML em leituras. O nome base (bn)
+// identifica a estação e o tempo base (bt) mais o tempo do registro (t)
+// identificam a leitura; registros sem tempo usam received. Registros de uma
+// mesma estação e instante formam uma leitura. Retorna também a quantidade
+// de registros inválidos ignorados
+func DecodeSenML(payload []byte, received int64) ([]Reading, int, error) {
+	var pack []senmlRecord
+	if err := json.Unmarshal(payload, &pack); err != nil {
+		return nil, 0, fmt.Errorf("JSON SenML inválido: %w", err)
+	}
+	readings, skipped, _ := decodePack(pack, received)
+	return readings, skipped, nil
+}
+
+// decodePack agrupa os registros de um pacote na ordem em que aparecem.
+// untimed indica que algum registro sem tempo absoluto usou received
+func decodePack(pack []senmlRecord, received int64) (readings []Reading, skipped int, untimed bool) {
+	index := map[string]int{}
+	station := DefaultStation
+	var baseTime float64
+
+	for _, record := range pack {
+		if bn, ok := record["bn"].(string); ok && bn != "" {
+			station = bn
+		}
+		if bt, ok := record["bt"].(float64); ok {
+			baseTime = bt
+		}
+
+		label, okLabel := record["n"].(string)
+		value, okValue := record["v"].(float64)
+		if !okLabel || !okValue {
+			skipped++
+			continue
+		}
+		metric, known := SenMLNames[label]
+		if !known {
+			continue
+		}
+
+		t, _ := record["t"].(float64)
+		timestamp := received
+		if resolved := baseTime + t; math.Abs(resolved) >= relativeTimeLimit {
+			timestamp = int64(math.Round(resolved))
+		} else {
+			timestamp = received + int64(math.Round(resolved))
+			untimed = true
+		}
+
+		key := fmt.Sprintf("%s\x00%d", station, timestamp)
+		i, ok := index[key]
+		if !ok {
+			i = len(readings)
+			index[key] = i
+			readings = append(readings, Reading{Station: station, Timestamp: timestamp, Values: map[string]float64{}})
+		}
+		readings[i].Values[metric] = value
+	}
+	return readings, skipped, untimed
+}
+
+// SenMLResult é o resultado da leitura de um arquivo SenML
+type SenMLResult struct {
+	Readings []Reading
+	Skipped  int        // registros malformados ignorados
+	Untimed  int        // pacotes com registros sem tempo absoluto
+	Errors   []RowError // pacotes rejeitados por não ter tempo absoluto
+}
+
+// ReadSenML lê SenML com um pacote (array de registros), um array de
+// pacotes ou vários pacotes em sequência, um por linha. Gravações do
+// comando record têm outro formato e são reproduzidas pelo comando replay.
+// Registros sem tempo absoluto usam received; com received zero, como em
+// dados históricos, os seus pacotes são rejeitados em Errors, numerados a
+// partir de 1. As leituras saem ordenadas por tempo
+func ReadSenML(r io.Reader, received int64) (SenMLResult, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var result SenMLResult
+	number := 0
+	for packs := 0; ; packs++ {
+		var value []json.RawMessage
+		if err := dec.Decode(&value); err == io.EOF {
+			break
+		} else if err != nil {
+			return result, fmt.Errorf("JSON SenML inválido no pacote %d: %w", packs+1, err)
+		}
+
+		var group [][]senmlRecord
+		if len(value) > 0 && bytes.HasPrefix(bytes.TrimSpace(value[0]), []byte("[")) {
+			for _, raw := range value {
+				var pack []senmlRecord
+				if err := json.Unmarshal(raw, &pack); err != nil {
+					return result, fmt.Errorf("JSON SenML inválido no pacote %d: %w", packs+1, err)
+				}
+				group = append(group, pack)
+			}
+		} else {
+			pack := make([]senmlRecord, 0, len(value))
+			for _, raw := range value {
+				var record senmlRecord
+				if err := json.Unmarshal(raw, &record); err != nil {
+					result.Skipped++
+					continue
+				}
+				pack = append(pack, record)
+			}
+			group = append(group, pack)
+		}
+
+		for _, pack := range group {
+			number++
+			decoded, invalid, untimed := decodePack(pack, received)
+			result.Skipped += invalid
+			if untimed && len(decoded) > 0 {
+				if received == 0 {
+					result.Errors = append(result.Errors, RowError{Line: number, Err: "pacote com registros sem tempo absoluto"})
+					continue
+				}
+				result.Untimed++
+			}
+			result.Readings = append(result.Readings, decoded...)
+		}
+	}
+
+	sort.SliceStable(result.Readings, func(i, j int) bool { return result.Readings[i].Timestamp < result.Readings[j].Timestamp })
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package meteorology
+
+import "math"
+
+// CropProfile define as temperaturas base e de corte para graus-dia
+type CropProfile struct {
+	Name     string  `json:"name"`
+	BaseTemp float64 `json:"base_temp"` // °C
+	CapTemp  float64 `json:"cap_temp"`  // °C, 0 indica sem limite superior
+}
+
+// CropProfiles são os perfis de cultura disponíveis por padrão
+var CropProfiles = map[string]CropProfile{
+	"milho":  {Name: "milho", BaseTemp: 10, CapTemp: 30},
+	"soja":   {Name: "soja", BaseTemp: 10, CapTemp: 30},
+	"trigo":  {Name: "trigo", BaseTemp: 0, CapTemp: 26},
+	"feijao": {Name: "feijao", BaseTemp: 10, CapTemp: 30},
+	"cafe":   {Name: "cafe", BaseTemp: 10, CapTemp: 34},
+	"uva":    {Name: "uva", BaseTemp: 10},
+}
+
+// Limites do modelo de horas de frio (0 a 7,2 °C)
+const (
+	ChillMin = 0.0
+	ChillMax = 7.2
+)
+
+// GrowingDegreeDays calcula os graus-dia pelo método da média modificada:
+// mínima e máxima são limitadas ao intervalo [base, corte] antes da média
+func GrowingDegreeDays(tMin, tMax float64, profile CropProfile) float64 {
+	clamp := func(t float64) float64 {
+		t = math.Max(t, profile.BaseTemp)
+		if profile.CapTemp > 0 {
+			t = math.Min(t, profile.CapTemp)
+		}
+		return t
+	}
+	return math.Max(0, (clamp(tMin)+clamp(tMax))/2-profile.BaseTemp)
+}
+
+// IsChillHour indica se a temperatura média de uma hora conta como hora de frio
+func IsChillHour(temperature float64) bool {
+	return temperature >= ChillMin && temperature <= ChillMax
+}
@@ -0,0 +1,72 @@
+package meteorology
+
+import "math"
+
+// Beaufort é um grau da escala de Beaufort. Code identifica a descrição
+// ("gentle_breeze") e WaveHeight é a altura provável das ondas em mar aberto (m)
+type Beaufort struct {
+	Number     int     `json:"number"`
+	Code       string  `json:"code"`
+	MinSpeed   float64 `json:"min_speed"` // m/s
+	WaveHeight float64 `json:"wave_height"`
+}
+
+// BeaufortScale são os 13 graus da escala (WMO), com a velocidade mínima
+// média em 10 minutos a 10 m de altura
+var BeaufortScale = []Beaufort{
+	{Number: 0, Code: "calm", MinSpeed: 0, WaveHeight: 0},
+	{Number: 1, Code: "light_air", MinSpeed: 0.3, WaveHeight: 0.1},
+	{Number: 2, Code: "light_breeze", MinSpeed: 1.6, WaveHeight: 0.2},
+	{Number: 3, Code: "gentle_breeze", MinSpeed: 3.4, WaveHeight: 0.6},
+	{Number: 4, Code: "moderate_breeze", MinSpeed: 5.5, WaveHeight: 1},
+	{Number: 5, Code: "fresh_breeze", MinSpeed: 8.0, WaveHeight: 2},
+	{Number: 6, Code: "strong_breeze", MinSpeed: 10.8, WaveHeight: 3},
+	{Number: 7, Code: "near_gale", MinSpeed: 13.9, WaveHeight: 4},
+	{Number: 8, Code: "gale", MinSpeed: 17.2, WaveHeight: 5.5},
+	{Number: 9, Code: "strong_gale", MinSpeed: 20.8, WaveHeight: 7},
+	{Number: 10, Code: "storm", MinSpeed: 24.5, WaveHeight: 9},
+	{Number: 11, Code: "violent_storm", MinSpeed: 28.5, WaveHeight: 11.5},
+	{Number: 12, Code: "hurricane", MinSpeed: 32.7, WaveHeight: 14},
+}
+
+// BeaufortFor retorna o grau da escala para a velocidade média (m/s)
+func BeaufortFor(speed float64) Beaufort {
+	for i := len(BeaufortScale) - 1; i > 0; i-- {
+		if speed >= BeaufortScale[i].MinSpeed {
+			return BeaufortScale[i]
+		}
+	}
+	return BeaufortScale[0]
+}
+
+// roughnessLength é o comprimento de rugosidade (m) de terreno aberto com
+// grama baixa, usado no perfil logarítmico do vento
+const roughnessLength = 0.03
+
+// WindAt10m converte a velocidade medida a height metros para a altura
+// padrão de 10 m pelo perfil logarítmico do vento
+func WindAt10m(speed, height float64) float64 {
+	if height <= roughnessLength || height == 10 {
+		return speed
+	}
+	return speed * math.Log(10/roughnessLength) / math.Log(height/roughnessLength)
+}
+
+// BeaufortAt retorna o grau da escala para a velocidade média (m/s) medida
+// por um anemômetro a height metros, convertida antes para 10 m
+func BeaufortAt(speed, height float64) Beaufort {
+	return BeaufortFor(WindAt10m(speed, height))
+}
+
+// GustFactorMinSpeed é a velocidade média mínima (m/s) para calcular o fator
+// de rajada; abaixo dela a razão é dominada pelo ruído do anemômetro
+const GustFactorMinSpeed = 1.0
+
+// GustFactor retorna a razão entre a rajada e a velocidade média. O segundo
+// valor é falso quando a média é baixa demais para o fator ser significativo
+func GustFactor(gust, mean float64) (float64, bool) {
+	if mean < GustFactorMinSpeed || gust < 0 {
+		return 0, false
+	}
+	return gust / mean, true
+}
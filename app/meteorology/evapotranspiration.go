@@ -0,0 +1,184 @@
+package meteorology
+
+import (
+	"math"
+	"time"
+)
+
+// Constantes da FAO-56 (Allen et al., 1998)
+const (
+	solarConstant   = 0.0820   // MJ m-2 min-1
+	stefanBoltzmann = 4.903e-9 // MJ K-4 m-2 dia-1
+	albedo          = 0.23     // cultura de referência (grama)
+)
+
+// Site descreve a localização da estação usada nos cálculos de radiação
+type Site struct {
+	Latitude   float64 // graus, negativo no hemisfério sul
+	Longitude  float64 // graus, negativo a oeste de Greenwich
+	Elevation  float64 // metros acima do nível do mar
+	WindHeight float64 // altura do anemômetro em metros
+}
+
+// ETHourlyInput são as médias horárias usadas na ET0 horária
+type ETHourlyInput struct {
+	Start          time.Time // início da hora
+	Temperature    float64   // °C
+	Humidity       float64   // %
+	WindSpeed      float64   // m/s na altura do anemômetro
+	SolarRadiation float64   // W/m²
+}
+
+// ETDailyInput são os valores diários usados na ET0 diária
+type ETDailyInput struct {
+	Date           time.Time // dia local
+	TempMin        float64   // °C
+	TempMax        float64   // °C
+	Humidity       float64   // % (média)
+	WindSpeed      float64   // m/s na altura do anemômetro (média)
+	SolarRadiation float64   // W/m² (média do dia)
+}
+
+// saturationVaporPressure retorna e°(T) em kPa (eq. 11)
+func saturationVaporPressure(t float64) float64 {
+	return 0.6108 * math.Exp(17.27*t/(t+237.3))
+}
+
+// vaporPressureSlope retorna Δ em kPa/°C (eq. 13)
+func vaporPressureSlope(t float64) float64 {
+	return 4098 * saturationVaporPressure(t) / math.Pow(t+237.3, 2)
+}
+
+// psychrometricConstant retorna γ em kPa/°C para a altitude (eq. 7 e 8)
+func psychrometricConstant(elevation float64) float64 {
+	pressure := 101.3 * math.Pow((293-0.0065*elevation)/293, 5.26)
+	return 0.000665 * pressure
+}
+
+// windAt2m converte a velocidade medida em z metros para 2 m (eq. 47)
+func windAt2m(speed, height float64) float64 {
+	if height <= 0 || height == 2 {
+		return speed
+	}
+	return speed * 4.87 / math.Log(67.8*height-5.42)
+}
+
+// solarGeometry retorna a distância relativa Terra-Sol, a declinação solar
+// e o ângulo do pôr do sol para o dia do ano (eq. 23, 24 e 25)
+func solarGeometry(dayOfYear int, latitude float64) (dr, delta, ws float64) {
+	j := float64(dayOfYear)
+	dr = 1 + 0.033*math.Cos(2*math.Pi/365*j)
+	delta = 0.409 * math.Sin(2*math.Pi/365*j-1.39)
+	x := -math.Tan(latitude) * math.Tan(delta)
+	ws = math.Acos(math.Max(-1, math.Min(1, x)))
+	return dr, delta, ws
+}
+
+// DailyExtraterrestrialRadiation retorna Ra em MJ m-2 dia-1 (eq. 21)
+func DailyExtraterrestrialRadiation(latitudeDeg float64, dayOfYear int) float64 {
+	phi := latitudeDeg * math.Pi / 180
+	dr, delta, ws := solarGeometry(dayOfYear, phi)
+	return 24 * 60 / math.Pi * solarConstant * dr *
+		(ws*math.Sin(phi)*math.Sin(delta) + math.Cos(phi)*math.Cos(delta)*math.Sin(ws))
+}
+
+// HourlyExtraterrestrialRadiation retorna Ra em MJ m-2 h-1 para a hora que
+// começa em start (eq. 28 a 33)
+func HourlyExtraterrestrialRadiation(site Site, start time.Time) float64 {
+	phi := site.Latitude * math.Pi / 180
+	dr, delta, ws := solarGeometry(start.YearDay(), phi)
+
+	// Correção sazonal do tempo solar (eq. 32 e 33)
+	b := 2 * math.Pi * float64(start.YearDay()-81) / 364
+	sc := 0.1645*math.Sin(2*b) - 0.1255*math.Cos(b) - 0.025*math.Sin(b)
+
+	// Longitudes em graus a oeste de Greenwich, como na FAO-56
+	_, offset := start.Zone()
+	lz := -float64(offset) / 3600 * 15
+	lm := -site.Longitude
+
+	mid := start.Add(30 * time.Minute)
+	t := float64(mid.Hour()) + float64(mid.Minute())/60
+	omega := math.Pi / 12 * ((t + 0.06667*(lz-lm) + sc) - 12)
+
+	w1 := math.Max(omega-math.Pi/24, -ws)
+	w2 := math.Min(omega+math.Pi/24, ws)
+	if w1 >= w2 {
+		return 0
+	}
+	ra := 12 * 60 / math.Pi * solarConstant * dr *
+		((w2-w1)*math.Sin(phi)*math.Sin(delta) + math.Cos(phi)*math.Cos(delta)*(math.Sin(w2)-math.Sin(w1)))
+	return math.Max(0, ra)
+}
+
+// clearSkyRadiation retorna Rso a partir de Ra e da altitude (eq. 37)
+func clearSkyRadiation(ra, elevation float64) float64 {
+	return (0.75 + 2e-5*elevation) * ra
+}
+
+// penmanMonteith combina os termos de radiação e aerodinâmico (eq. 6 e 53)
+func penmanMonteith(delta, gamma, rn, g, t, u2, vpd, coef, cd float64) float64 {
+	et0 := (0.408*delta*(rn-g) + gamma*coef/(t+273)*u2*vpd) /
+		(delta + gamma*(1+cd*u2))
+	return math.Max(0, et0)
+}
+
+// DailyET0 calcula a evapotranspiração de referência diária (mm/dia) pela
+// equação de Penman-Monteith FAO-56, desprezando o fluxo de calor no solo
+func DailyET0(site Site, in ETDailyInput) float64 {
+	tMean := (in.TempMax + in.TempMin) / 2
+	es := (saturationVaporPressure(in.TempMax) + saturationVaporPressure(in.TempMin)) / 2
+	ea := clampHumidity(in.Humidity) / 100 * es
+
+	rs := in.SolarRadiation * 0.0864 // W/m² médio para MJ m-2 dia-1
+	ra := DailyExtraterrestrialRadiation(site.Latitude, in.Date.YearDay())
+	rso := clearSkyRadiation(ra, site.Elevation)
+
+	ratio := 1.0
+	if rso > 0 {
+		ratio = math.Min(1, rs/rso)
+	}
+	tMaxK := math.Pow(in.TempMax+273.16, 4)
+	tMinK := math.Pow(in.TempMin+273.16, 4)
+	rnl := stefanBoltzmann * (tMaxK + tMinK) / 2 * (0.34 - 0.14*math.Sqrt(ea)) * (1.35*ratio - 0.35)
+	rn := (1-albedo)*rs - rnl
+
+	return penmanMonteith(vaporPressureSlope(tMean), psychrometricConstant(site.Elevation),
+		rn, 0, tMean, windAt2m(in.WindSpeed, site.WindHeight), es-ea, 900, 0.34)
+}
+
+// HourlyET0 calcula a ET0 horária (mm/h) de uma série em ordem cronológica.
+// À noite a razão Rs/Rso não é definida, então usa-se a última razão diurna
+// (FAO-56, eq. 39)
+func HourlyET0(site Site, inputs []ETHourlyInput) []float64 {
+	gamma := psychrometricConstant(site.Elevation)
+	sigma := stefanBoltzmann / 24
+	ratio := 0.8
+
+	result := make([]float64, len(inputs))
+	for i, in := range inputs {
+		es := saturationVaporPressure(in.Temperature)
+		ea := clampHumidity(in.Humidity) / 100 * es
+
+		rs := in.SolarRadiation * 0.0036 // W/m² para MJ m-2 h-1
+		ra := HourlyExtraterrestrialRadiation(site, in.Start)
+		rso := clearSkyRadiation(ra, site.Elevation)
+		daytime := rso > 0.05
+		if daytime {
+			ratio = math.Max(0.25, math.Min(1, rs/rso))
+		}
+
+		rnl := sigma * math.Pow(in.Temperature+273.16, 4) * (0.34 - 0.14*math.Sqrt(ea)) * (1.35*ratio - 0.35)
+		rn := (1-albedo)*rs - rnl
+
+		// Fluxo de calor no solo (eq. 45 e 46)
+		g := 0.5 * rn
+		if daytime {
+			g = 0.1 * rn
+		}
+
+		result[i] = penmanMonteith(vaporPressureSlope(in.Temperature), gamma,
+			rn, g, in.Temperature, windAt2m(in.WindSpeed, site.WindHeight), es-ea, 37, 0.34)
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package meteorology
+
+import (
+	"math"
+	"projeto/app/units"
+)
+
+// Constantes da fórmula de Magnus (Alduchov & Eskridge, 1996)
+const (
+	magnusA = 17.625
+	magnusB = 243.04
+)
+
+// clampHumidity limita a umidade relativa ao intervalo físico, evitando
+// log(0) quando o sensor reporta 0%
+func clampHumidity(humidity float64) float64 {
+	return math.Max(1, math.Min(100, humidity))
+}
+
+// VaporPressure retorna a pressão de vapor atual em hPa a partir da
+// temperatura (°C) e umidade relativa (%)
+func VaporPressure(temperature, humidity float64) float64 {
+	return clampHumidity(humidity) / 100 * 6.112 * math.Exp(magnusA*temperature/(magnusB+temperature))
+}
+
+// DewPoint calcula o ponto de orvalho (°C) pela fórmula de Magnus
+func DewPoint(temperature, humidity float64) float64 {
+	gamma := math.Log(clampHumidity(humidity)/100) + magnusA*temperature/(magnusB+temperature)
+	return magnusB * gamma / (magnusA - gamma)
+}
+
+// HeatIndex calcula o índice de calor (°C) pelo algoritmo do NWS: fórmula
+// simplificada de Steadman e, acima de 80 °F, regressão de Rothfusz com os
+// ajustes para umidade baixa e alta
+func HeatIndex(temperature, humidity float64) float64 {
+	t := units.CelsiusToFahrenheit(temperature)
+	rh := clampHumidity(humidity)
+
+	hi := 0.5 * (t + 61.0 + (t-68.0)*1.2 + rh*0.094)
+	if (hi+t)/2 < 80 {
+		return units.FahrenheitToCelsius(hi)
+	}
+
+	hi = -42.379 + 2.04901523*t + 10.14333127*rh -
+		0.22475541*t*rh - 0.00683783*t*t -
+		0.05481717*rh*rh + 0.00122874*t*t*rh +
+		0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+	switch {
+	case rh < 13 && t >= 80 && t <= 112:
+		hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+	case rh > 85 && t >= 80 && t <= 87:
+		hi += (rh - 85) / 10 * (87 - t) / 5
+	}
+
+	return units.FahrenheitToCelsius(hi)
+}
+
+// WindChill calcula a sensação de frio pelo vento (°C) com a fórmula do
+// NWS/Environment Canada. Fora do domínio da fórmula (temperatura acima de
+// 10 °C ou vento abaixo de 4,8 km/h) retorna a própria temperatura
+func WindChill(temperature, windSpeedKMH float64) float64 {
+	if temperature > 10 || windSpeedKMH <= 4.8 {
+		return temperature
+	}
+	v := math.Pow(windSpeedKMH, 0.16)
+	return 13.12 + 0.6215*temperature - 11.37*v + 0.3965*temperature*v
+}
+
+// ApparentTemperature calcula a temperatura aparente de Steadman (°C), como
+// usada pelo Bureau of Meteorology australiano, sem o termo de radiação
+func ApparentTemperature(temperature, humidity, windSpeedMS float64) float64 {
+	e := VaporPressure(temperature, humidity)
+	return temperature + 0.33*e - 0.70*windSpeedMS - 4.00
+}
+
+// FeelsLike retorna a sensação térmica (°C): sensação do vento no frio,
+// índice de calor acima de 26,7 °C (80 °F) e a temperatura do ar entre eles
+func FeelsLike(temperature, humidity, windSpeedKMH float64) float64 {
+	switch {
+	case temperature <= 10 && windSpeedKMH > 4.8:
+		return WindChill(temperature, windSpeedKMH)
+	case temperature >= 26.7:
+		return HeatIndex(temperature, humidity)
+	default:
+		return temperature
+	}
+}
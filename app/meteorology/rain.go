@@ -0,0 +1,93 @@
+package meteorology
+
+import "time"
+
+// RainSample é uma leitura do pluviômetro acumulativo (mm) em um instante UNIX
+type RainSample struct {
+	Timestamp int64
+	Level     float64
+}
+
+// RainAccumulation resume a precipitação calculada a partir do contador
+type RainAccumulation struct {
+	Rate       float64 `json:"rain_rate"`        // mm/h na janela de RainRateWindow
+	LastHour   float64 `json:"rain_last_hour"`   // mm nos últimos 60 minutos
+	Today      float64 `json:"rain_today"`       // mm desde o início do dia local
+	Storm      float64 `json:"rain_storm"`       // mm do evento de chuva atual
+	StormStart int64   `json:"rain_storm_start"` // início do evento (0 se não houver)
+	Resets     int     `json:"rain_resets"`      // reinícios do contador detectados
+}
+
+const (
+	// RainRateWindow é a janela usada para estimar a taxa de chuva
+	RainRateWindow = 15 * time.Minute
+	// StormGap é o intervalo sem chuva que encerra um evento
+	StormGap = 6 * time.Hour
+	// rainResetTolerance ignora pequenas oscilações do contador para baixo
+	rainResetTolerance = 0.01
+)
+
+// RainIncrements converte as leituras acumulativas em incrementos por amostra.
+// Quando o contador diminui (reinício do equipamento ou estouro), a leitura
+// atual é tratada como o volume acumulado desde o reinício
+func RainIncrements(samples []RainSample) ([]float64, int) {
+	increments := make([]float64, len(samples))
+	resets := 0
+	for i := 1; i < len(samples); i++ {
+		diff := samples[i].Level - samples[i-1].Level
+		switch {
+		case diff >= 0:
+			increments[i] = diff
+		case diff < -rainResetTolerance:
+			increments[i] = samples[i].Level
+			resets++
+		}
+	}
+	return increments, resets
+}
+
+// AccumulateRain calcula taxa e acumulados de chuva. As amostras devem estar
+// em ordem cronológica; dayStart é o início do dia local em UNIX
+func AccumulateRain(samples []RainSample, now time.Time, dayStart int64) RainAccumulation {
+	increments, resets := RainIncrements(samples)
+	acc := RainAccumulation{Resets: resets}
+
+	nowUnix := now.Unix()
+	rateStart := nowUnix - int64(RainRateWindow.Seconds())
+	hourStart := nowUnix - 3600
+	gap := int64(StormGap.Seconds())
+
+	var lastWet int64
+	for i, inc := range increments {
+		ts := samples[i].Timestamp
+		if inc <= 0 {
+			continue
+		}
+		if ts > rateStart {
+			acc.Rate += inc
+		}
+		if ts > hourStart {
+			acc.LastHour += inc
+		}
+		if ts >= dayStart {
+			acc.Today += inc
+		}
+
+		// Um novo evento começa após StormGap sem chuva
+		if lastWet == 0 || ts-lastWet > gap {
+			acc.Storm = 0
+			acc.StormStart = ts
+		}
+		acc.Storm += inc
+		lastWet = ts
+	}
+
+	// O evento termina se não chove há mais de StormGap
+	if lastWet == 0 || nowUnix-lastWet > gap {
+		acc.Storm = 0
+		acc.StormStart = 0
+	}
+
+	acc.Rate *= float64(time.Hour) / float64(RainRateWindow)
+	return acc
+}
@@ -0,0 +1,187 @@
+package meteorology
+
+import (
+	"math"
+	"projeto/app/units"
+)
+
+// WindSample é uma leitura de vento: velocidade em m/s e direção de origem
+// em radianos, medida a partir do norte no sentido horário
+type WindSample struct {
+	Timestamp int64
+	Speed     float64
+	Direction float64
+	Gust      float64 // rajada (m/s); zero quando a estação não envia
+}
+
+// WindStats agrega um conjunto de leituras com estatística circular
+type WindStats struct {
+	Start         int64   `json:"start"`
+	Count         int     `json:"count"`
+	MeanSpeed     float64 `json:"mean_speed"`     // média escalar (m/s)
+	VectorSpeed   float64 `json:"vector_speed"`   // módulo do vetor médio (m/s)
+	MeanDirection float64 `json:"mean_direction"` // direção média vetorial (rad)
+	Steadiness    float64 `json:"steadiness"`     // 0 (variável) a 1 (constante)
+	GustMax       float64 `json:"gust_max"`       // maior rajada, ou velocidade sem rajada (m/s)
+	GustFactor    float64 `json:"gust_factor"`    // rajada máxima / média escalar (0 se indefinido)
+}
+
+// SectorNames são os 16 pontos da rosa dos ventos, a partir do norte
+var SectorNames = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// SpeedClass é uma faixa de velocidade da rosa dos ventos, em km/h
+type SpeedClass struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max,omitempty"` // 0 indica faixa aberta
+	Label string  `json:"label"`
+}
+
+// DefaultSpeedClasses são as faixas usadas pela rosa dos ventos
+var DefaultSpeedClasses = []SpeedClass{
+	{Min: 1, Max: 5, Label: "1-5 km/h"},
+	{Min: 5, Max: 10, Label: "5-10 km/h"},
+	{Min: 10, Max: 20, Label: "10-20 km/h"},
+	{Min: 20, Max: 30, Label: "20-30 km/h"},
+	{Min: 30, Max: 40, Label: "30-40 km/h"},
+	{Min: 40, Label: ">40 km/h"},
+}
+
+// CalmThresholdKMH é a velocidade abaixo da qual a leitura é considerada calmaria
+const CalmThresholdKMH = 1.0
+
+// NormalizeAngle traz um ângulo em radianos para o intervalo [0, 2π)
+func NormalizeAngle(rad float64) float64 {
+	rad = math.Mod(rad, 2*math.Pi)
+	if rad < 0 {
+		rad += 2 * math.Pi
+	}
+	return rad
+}
+
+// AggregateWind calcula a direção média vetorial (ponderada pela velocidade)
+// e a constância do vento, razão entre o vetor médio e a média escalar.
+// Se todas as leituras forem de calmaria, a direção usa vetores unitários
+func AggregateWind(samples []WindSample) WindStats {
+	stats := WindStats{Count: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+	stats.Start = samples[0].Timestamp
+
+	var sumSpeed, sumX, sumY, unitX, unitY float64
+	for _, s := range samples {
+		stats.GustMax = math.Max(stats.GustMax, math.Max(s.Gust, s.Speed))
+		sumSpeed += s.Speed
+		sumX += s.Speed * math.Sin(s.Direction)
+		sumY += s.Speed * math.Cos(s.Direction)
+		unitX += math.Sin(s.Direction)
+		unitY += math.Cos(s.Direction)
+	}
+
+	n := float64(len(samples))
+	stats.MeanSpeed = sumSpeed / n
+	stats.VectorSpeed = math.Hypot(sumX, sumY) / n
+	stats.GustFactor, _ = GustFactor(stats.GustMax, stats.MeanSpeed)
+
+	if sumSpeed > 0 {
+		stats.MeanDirection = NormalizeAngle(math.Atan2(sumX, sumY))
+		stats.Steadiness = stats.VectorSpeed / stats.MeanSpeed
+	} else {
+		stats.MeanDirection = NormalizeAngle(math.Atan2(unitX, unitY))
+		stats.Steadiness = math.Hypot(unitX, unitY) / n
+	}
+	return stats
+}
+
+// BucketWind agrupa as leituras (em ordem cronológica) em intervalos de
+// bucketSeconds e agrega cada intervalo
+func BucketWind(samples []WindSample, bucketSeconds int64) []WindStats {
+	if bucketSeconds <= 0 {
+		return nil
+	}
+
+	var buckets []WindStats
+	for i := 0; i < len(samples); {
+		start := samples[i].Timestamp - samples[i].Timestamp%bucketSeconds
+		j := i
+		for j < len(samples) && samples[j].Timestamp < start+bucketSeconds {
+			j++
+		}
+		stats := AggregateWind(samples[i:j])
+		stats.Start = start
+		buckets = append(buckets, stats)
+		i = j
+	}
+	return buckets
+}
+
+// Sector retorna o índice (0-15) do setor da rosa dos ventos para a direção
+func Sector(rad float64) int {
+	return int(math.Floor(NormalizeAngle(rad)/(math.Pi/8)+0.5)) % 16
+}
+
+// WindRoseSector contém as contagens de um setor por faixa de velocidade
+type WindRoseSector struct {
+	Sector      string    `json:"sector"`
+	Degrees     float64   `json:"degrees"`
+	Count       int       `json:"count"`
+	Frequency   float64   `json:"frequency"`   // % do total de leituras
+	Counts      []int     `json:"counts"`      // por faixa de velocidade
+	Frequencies []float64 `json:"frequencies"` // % do total, por faixa
+}
+
+// WindRose é a distribuição de frequências por setor e faixa de velocidade
+type WindRose struct {
+	Total         int              `json:"total"`
+	Calm          int              `json:"calm"`
+	CalmFrequency float64          `json:"calm_frequency"`
+	SpeedClasses  []SpeedClass     `json:"speed_classes"`
+	Sectors       []WindRoseSector `json:"sectors"`
+}
+
+// BuildWindRose distribui as leituras pelos 16 setores e pelas faixas de
+// velocidade. Leituras abaixo de CalmThresholdKMH contam como calmaria
+func BuildWindRose(samples []WindSample, classes []SpeedClass) WindRose {
+	rose := WindRose{Total: len(samples), SpeedClasses: classes}
+	for i, name := range SectorNames {
+		rose.Sectors = append(rose.Sectors, WindRoseSector{
+			Sector:      name,
+			Degrees:     float64(i) * 22.5,
+			Counts:      make([]int, len(classes)),
+			Frequencies: make([]float64, len(classes)),
+		})
+	}
+
+	for _, s := range samples {
+		kmh := units.MSToKMH(s.Speed)
+		if kmh < CalmThresholdKMH {
+			rose.Calm++
+			continue
+		}
+		sector := &rose.Sectors[Sector(s.Direction)]
+		sector.Count++
+		for c := len(classes) - 1; c >= 0; c-- {
+			if kmh >= classes[c].Min {
+				sector.Counts[c]++
+				break
+			}
+		}
+	}
+
+	if rose.Total == 0 {
+		return rose
+	}
+	total := float64(rose.Total)
+	rose.CalmFrequency = float64(rose.Calm) / total * 100
+	for i := range rose.Sectors {
+		sector := &rose.Sectors[i]
+		sector.Frequency = float64(sector.Count) / total * 100
+		for c, count := range sector.Counts {
+			sector.Frequencies[c] = float64(count) / total * 100
+		}
+	}
+	return rose
+}
@@ -0,0 +1,160 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// RecordedMessage é uma mensagem MQTT gravada, uma por linha (NDJSON). O
+// payload é guardado como texto quando é UTF-8 válido e em base64 caso
+// contrário, para reproduzir exatamente os bytes recebidos
+type RecordedMessage struct {
+	Received      time.Time `json:"received"`
+	Topic         string    `json:"topic"`
+	QoS           byte      `json:"qos"`
+	Retained      bool      `json:"retained,omitempty"`
+	Payload       *string   `json:"payload,omitempty"`
+	PayloadBase64 []byte    `json:"payload_base64,omitempty"`
+}
+
+// Bytes retorna o payload original
+func (m RecordedMessage) Bytes() []byte {
+	if m.Payload != nil {
+		return []byte(*m.Payload)
+	}
+	return m.PayloadBase64
+}
+
+// Recorder grava as mensagens recebidas em um arquivo, acrescentando ao fim
+type Recorder struct {
+	mu    sync.Mutex
+	file  *os.File
+	count int
+}
+
+// OpenRecorder abre (ou cria) o arquivo de gravação
+func OpenRecorder(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{file: file}, nil
+}
+
+// Record grava a mensagem com o tópico e o instante de recebimento
+func (r *Recorder) Record(msg mqtt.Message, received time.Time) error {
+	recorded := RecordedMessage{
+		Received: received,
+		Topic:    msg.Topic(),
+		QoS:      msg.Qos(),
+		Retained: msg.Retained(),
+	}
+	if payload := msg.Payload(); utf8.Valid(payload) {
+		text := string(payload)
+		recorded.Payload = &text
+	} else {
+		recorded.PayloadBase64 = payload
+	}
+
+	line, err := json.Marshal(recorded)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	r.count++
+	return nil
+}
+
+// Count retorna quantas mensagens foram gravadas
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// Close fecha o arquivo de gravação
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+var (
+	recorderMu sync.RWMutex
+	recorder   *Recorder
+)
+
+// setRecorder ativa a gravação das mensagens recebidas pelo cliente principal
+func setRecorder(r *Recorder) {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+	recorder = r
+}
+
+// activeRecorder retorna o gravador ativo, ou nil
+func activeRecorder() *Recorder {
+	recorderMu.RLock()
+	defer recorderMu.RUnlock()
+	return recorder
+}
+
+// ReadRecording lê uma gravação linha a linha, chamando fn para cada mensagem
+func ReadRecording(r io.Reader, fn func(RecordedMessage) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var msg RecordedMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return fmt.Errorf("linha %d: %w", line, err)
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Record conecta ao broker e grava as mensagens do tópico até ctx terminar,
+// sem processá-las. Retorna a quantidade de mensagens gravadas
+func Record(ctx context.Context, broker, topic string, r *Recorder) (int, error) {
+	opts := mqtt.NewClientOptions()
+	opts.AddBroker(broker)
+	opts.SetClientID(fmt.Sprintf("GoMQTTRecorder-%d", os.Getpid()))
+	opts.SetAutoReconnect(true)
+	opts.SetOnConnectHandler(func(c mqtt.Client) {
+		slog.Info("Conectado ao broker MQTT, gravando", "broker", broker, "topic", topic)
+		token := c.Subscribe(topic, 0, func(_ mqtt.Client, msg mqtt.Message) {
+			if err := r.Record(msg, time.Now()); err != nil {
+				slog.Error("Erro ao gravar mensagem", "topic", msg.Topic(), "err", err)
+			}
+		})
+		if token.Wait() && token.Error() != nil {
+			slog.Error("Erro na inscrição", "topic", topic, "err", token.Error())
+		}
+	})
+
+	c := mqtt.NewClient(opts)
+	if token := c.Connect(); token.Wait() && token.Error() != nil {
+		return 0, fmt.Errorf("falha na conexão MQTT: %w", token.Error())
+	}
+	<-ctx.Done()
+	c.Disconnect(250)
+	return r.Count(), nil
+}
@@ -0,0 +1,247 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"projeto/app/alerts"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dispatcher distribui os alertas para os canais configurados com novas
+// tentativas, deduplicação e limite de envios por canal. Notificações acima
+// do limite são adiadas, nunca descartadas, e saem na ordem em que chegaram
+type Dispatcher struct {
+	Notifiers   []Notifier
+	MaxAttempts int           // tentativas por entrega
+	Backoff     time.Duration // espera inicial entre tentativas, dobrada a cada falha
+	DedupWindow time.Duration // período em que o mesmo estado não é reenviado
+	RateLimit   int           // envios por minuto por canal (0 desativa)
+	Timeout     time.Duration // prazo de cada tentativa
+
+	mu      sync.Mutex
+	sent    map[string]sentState // último estado enviado por canal, regra e estação
+	rate    map[string][]time.Time
+	pending map[string][]alerts.Alert // adiadas pelo limite, por canal
+}
+
+// NewDispatcher cria um distribuidor com os valores padrão
+func NewDispatcher(notifiers ...Notifier) *Dispatcher {
+	return &Dispatcher{
+		Notifiers:   notifiers,
+		MaxAttempts: 3,
+		Backoff:     2 * time.Second,
+		DedupWindow: time.Hour,
+		RateLimit:   10,
+		Timeout:     15 * time.Second,
+		sent:        make(map[string]sentState),
+		rate:        make(map[string][]time.Time),
+		pending:     make(map[string][]alerts.Alert),
+	}
+}
+
+// Dispatch envia o alerta a todos os canais em segundo plano
+func (d *Dispatcher) Dispatch(alert alerts.Alert) {
+	for _, n := range d.Notifiers {
+		d.submit(n, alert)
+	}
+}
+
+// sentState é o último estado enviado de uma regra em uma estação
+type sentState struct {
+	state string
+	at    time.Time
+}
+
+// submit aplica a deduplicação e o limite de envios antes da entrega. Só é
+// suprimida a repetição do último estado enviado da regra na estação (pelo
+// id não daria, pois cada novo disparo gera um alerta com outro id); um
+// novo firing após um resolved sempre sai
+func (d *Dispatcher) submit(n Notifier, alert alerts.Alert) {
+	channel := n.Name()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+
+	for key, last := range d.sent {
+		if now.Sub(last.at) > d.DedupWindow {
+			delete(d.sent, key)
+		}
+	}
+	key := fmt.Sprintf("%s:%s:%s", channel, alert.RuleID, alert.Station)
+	if last, ok := d.sent[key]; ok && last.state == alert.State {
+		slog.Debug("Notificação duplicada ignorada", "channel", channel, "alert", alert.ID, "state", alert.State)
+		return
+	}
+	d.sent[key] = sentState{state: alert.State, at: now}
+
+	// Com notificações já adiadas, a nova entra na fila atrás delas para que
+	// um resolved nunca saia antes do firing correspondente
+	if len(d.pending[channel]) > 0 || !d.take(channel, now) {
+		if len(d.pending[channel]) == 0 {
+			d.schedule(n, now)
+		}
+		d.pending[channel] = append(d.pending[channel], alert)
+		slog.Warn("Limite de notificações atingido, envio adiado", "channel", channel, "alert", alert.ID, "rule", alert.RuleID)
+		return
+	}
+	go d.deliver(n, alert)
+}
+
+// take reserva um envio no limite do canal. Deve ser chamado com d.mu travado
+func (d *Dispatcher) take(channel string, now time.Time) bool {
+	if d.RateLimit <= 0 {
+		return true
+	}
+	recent := d.rate[channel][:0]
+	for _, at := range d.rate[channel] {
+		if now.Sub(at) < time.Minute {
+			recent = append(recent, at)
+		}
+	}
+	d.rate[channel] = recent
+	if len(recent) >= d.RateLimit {
+		return false
+	}
+	d.rate[channel] = append(recent, now)
+	return true
+}
+
+// schedule agenda o envio das notificações adiadas para quando o envio mais
+// antigo sair da janela do limite. Deve ser chamado com d.mu travado
+func (d *Dispatcher) schedule(n Notifier, now time.Time) {
+	wait := time.Second
+	if recent := d.rate[n.Name()]; len(recent) > 0 {
+		wait = recent[0].Add(time.Minute).Sub(now)
+	}
+	time.AfterFunc(wait, func() { d.flush(n) })
+}
+
+// flush envia as notificações adiadas que cabem no limite e reagenda o resto
+func (d *Dispatcher) flush(n Notifier) {
+	channel := n.Name()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+
+	queue := d.pending[channel]
+	for len(queue) > 0 && d.take(channel, now) {
+		go d.deliver(n, queue[0])
+		queue = queue[1:]
+	}
+	d.pending[channel] = queue
+	if len(queue) > 0 {
+		d.schedule(n, now)
+	}
+}
+
+// deliver tenta entregar a notificação com backoff exponencial
+func (d *Dispatcher) deliver(n Notifier, alert alerts.Alert) {
+	wait := d.Backoff
+	for attempt := 1; attempt <= d.MaxAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), d.Timeout)
+		err := n.Notify(ctx, alert)
+		cancel()
+		if err == nil {
+			slog.Info("Notificação enviada", "channel", n.Name(), "alert", alert.ID,
+				"rule", alert.RuleID, "station", alert.Station, "state", alert.State)
+			return
+		}
+
+		slog.Warn("Falha ao enviar notificação", "channel", n.Name(), "alert", alert.ID,
+			"attempt", attempt, "err", err)
+		if attempt < d.MaxAttempts {
+			time.Sleep(wait)
+			wait *= 2
+		}
+	}
+	slog.Error("Notificação descartada após tentativas", "channel", n.Name(), "alert", alert.ID, "rule", alert.RuleID)
+}
+
+// Setup configura os canais a partir das variáveis de ambiente e os liga ao
+// motor de alertas:
+//
+//	ALERT_WEBHOOK_URL, ALERT_WEBHOOK_TEMPLATE (arquivo), ALERT_WEBHOOK_TOKEN
+//	SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASSWORD, SMTP_FROM, SMTP_TO (separados por vírgula)
+//	ALERT_MQTT_TOPIC
+//	NOTIFY_RATE_LIMIT (envios por minuto por canal)
+func Setup(engine *alerts.Engine, publish func(topic string, payload []byte) error) {
+	var notifiers []Notifier
+
+	defaultTemplate, err := ParseTemplate("default", DefaultWebhookTemplate)
+	if err != nil {
+		slog.Error("Template padrão de notificação inválido", "err", err)
+		return
+	}
+
+	if url := os.Getenv("ALERT_WEBHOOK_URL"); url != "" {
+		webhook := &WebhookNotifier{URL: url, Template: defaultTemplate, Headers: map[string]string{}}
+		if path := os.Getenv("ALERT_WEBHOOK_TEMPLATE"); path != "" {
+			content, err := os.ReadFile(path)
+			if err == nil {
+				webhook.Template, err = ParseTemplate(path, string(content))
+			}
+			if err != nil {
+				slog.Error("Template do webhook inválido", "file", path, "err", err)
+				return
+			}
+		}
+		if token := os.Getenv("ALERT_WEBHOOK_TOKEN"); token != "" {
+			webhook.Headers["Authorization"] = "Bearer " + token
+		}
+		webhook.Client = &http.Client{Timeout: 10 * time.Second}
+		notifiers = append(notifiers, webhook)
+	}
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "25"
+		}
+		var to []string
+		for _, addr := range strings.Split(os.Getenv("SMTP_TO"), ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				to = append(to, addr)
+			}
+		}
+		if len(to) == 0 {
+			slog.Warn("SMTP_HOST definido sem destinatários em SMTP_TO")
+		} else {
+			notifiers = append(notifiers, &SMTPNotifier{
+				Addr:     host + ":" + port,
+				Username: os.Getenv("SMTP_USER"),
+				Password: os.Getenv("SMTP_PASSWORD"),
+				From:     os.Getenv("SMTP_FROM"),
+				To:       to,
+			})
+		}
+	}
+
+	if topic := os.Getenv("ALERT_MQTT_TOPIC"); topic != "" && publish != nil {
+		notifiers = append(notifiers, &MQTTNotifier{Topic: topic, Template: defaultTemplate, Publish: publish})
+	}
+
+	if len(notifiers) == 0 {
+		slog.Info("Nenhum canal de notificação configurado")
+		return
+	}
+
+	dispatcher := NewDispatcher(notifiers...)
+	if value := os.Getenv("NOTIFY_RATE_LIMIT"); value != "" {
+		if limit, err := strconv.Atoi(value); err == nil && limit >= 0 {
+			dispatcher.RateLimit = limit
+		}
+	}
+	engine.OnChange(dispatcher.Dispatch)
+
+	names := make([]string, len(notifiers))
+	for i, n := range notifiers {
+		names[i] = n.Name()
+	}
+	slog.Info("Canais de notificação configurados", "channels", strings.Join(names, ","))
+}
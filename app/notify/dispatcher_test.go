@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"projeto/app/alerts"
+	"testing"
+	"time"
+)
+
+// recorder é um canal de teste que guarda os alertas recebidos
+type recorder struct {
+	received chan alerts.Alert
+}
+
+func newRecorder() *recorder {
+	return &recorder{received: make(chan alerts.Alert, 16)}
+}
+
+func (r *recorder) Name() string { return "test" }
+
+func (r *recorder) Notify(ctx context.Context, alert alerts.Alert) error {
+	r.received <- alert
+	return nil
+}
+
+// expect espera n alertas do canal e falha se chegar algum a mais
+func (r *recorder) expect(t *testing.T, n int, timeout time.Duration) []alerts.Alert {
+	t.Helper()
+	var got []alerts.Alert
+	deadline := time.After(timeout)
+	for len(got) < n {
+		select {
+		case a := <-r.received:
+			got = append(got, a)
+		case <-deadline:
+			t.Fatalf("recebidos %d alertas, esperados %d", len(got), n)
+		}
+	}
+	select {
+	case a := <-r.received:
+		t.Fatalf("alerta inesperado: %+v", a)
+	case <-time.After(100 * time.Millisecond):
+	}
+	return got
+}
+
+func TestDispatcherDeduplicatesRepeatedState(t *testing.T) {
+	r := newRecorder()
+	d := NewDispatcher(r)
+
+	// A repetição do último estado enviado é suprimida, mesmo com outro id
+	d.Dispatch(alerts.Alert{ID: 1, RuleID: "vento", Station: "konda", State: "firing"})
+	d.Dispatch(alerts.Alert{ID: 2, RuleID: "vento", Station: "konda", State: "firing"})
+	d.Dispatch(alerts.Alert{ID: 3, RuleID: "vento", Station: "outra", State: "firing"})
+	got := r.expect(t, 2, time.Second)
+	ids := map[int64]bool{}
+	for _, a := range got {
+		ids[a.ID] = true
+	}
+	if !ids[1] || !ids[3] {
+		t.Fatalf("alertas entregues: %+v", got)
+	}
+}
+
+func TestDispatcherDeliversRefire(t *testing.T) {
+	r := newRecorder()
+	d := NewDispatcher(r)
+
+	// Disparo, resolução e novo disparo dentro da janela saem todos, para
+	// que o destinatário não fique achando que o alerta está resolvido
+	transitions := []alerts.Alert{
+		{ID: 1, RuleID: "vento", Station: "konda", State: "firing"},
+		{ID: 1, RuleID: "vento", Station: "konda", State: "resolved"},
+		{ID: 2, RuleID: "vento", Station: "konda", State: "firing"},
+		{ID: 2, RuleID: "vento", Station: "konda", State: "resolved"},
+	}
+	for _, a := range transitions {
+		d.Dispatch(a)
+		r.expect(t, 1, time.Second)
+	}
+}
+
+func TestDispatcherDefersRateLimited(t *testing.T) {
+	r := newRecorder()
+	d := NewDispatcher(r)
+	d.RateLimit = 2
+
+	// Dois envios que saem da janela de um minuto em instantes
+	almostExpired := time.Now().Add(-time.Minute + 200*time.Millisecond)
+	d.rate[r.Name()] = []time.Time{almostExpired, almostExpired}
+
+	d.Dispatch(alerts.Alert{ID: 1, RuleID: "uv", Station: "konda", State: "firing"})
+	d.Dispatch(alerts.Alert{ID: 1, RuleID: "uv", Station: "konda", State: "resolved"})
+
+	select {
+	case a := <-r.received:
+		t.Fatalf("alerta enviado acima do limite: %+v", a)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	got := r.expect(t, 2, 2*time.Second)
+	states := map[string]bool{}
+	for _, a := range got {
+		states[a.State] = true
+	}
+	if !states["firing"] || !states["resolved"] {
+		t.Fatalf("alertas adiados não entregues: %+v", got)
+	}
+}
+
+func TestDispatcherKeepsOrderBehindDeferred(t *testing.T) {
+	r := newRecorder()
+	d := NewDispatcher(r)
+	d.RateLimit = 1
+	d.rate[r.Name()] = []time.Time{time.Now()}
+
+	d.Dispatch(alerts.Alert{ID: 1, RuleID: "uv", Station: "konda", State: "firing"})
+	d.Dispatch(alerts.Alert{ID: 1, RuleID: "uv", Station: "konda", State: "resolved"})
+
+	d.mu.Lock()
+	queue := append([]alerts.Alert(nil), d.pending[r.Name()]...)
+	d.mu.Unlock()
+	if len(queue) != 2 || queue[0].State != "firing" || queue[1].State != "resolved" {
+		t.Fatalf("fila de adiados: %+v", queue)
+	}
+}
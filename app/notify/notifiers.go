@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"projeto/app/alerts"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Notifier entrega um alerta por um canal específico
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, alert alerts.Alert) error
+}
+
+// templateFuncs são as funções disponíveis nos templates de notificação
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"time": func(ts int64) string {
+		return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+	},
+}
+
+// DefaultWebhookTemplate é o corpo JSON enviado quando nenhum template é configurado
+const DefaultWebhookTemplate = `{
+  "id": {{ .ID }},
+  "rule_id": {{ json .RuleID }},
+  "rule_name": {{ json .RuleName }},
+  "station": {{ json .Station }},
+  "state": {{ json .State }},
+  "severity": {{ json .Severity }},
+  "metric": {{ json .Metric }},
+  "value": {{ .Value }},
+  "threshold": {{ .Threshold }},
+  "started_at": {{ json (time .StartedAt) }}
+}`
+
+// ParseTemplate compila um template de notificação
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// render executa o template com o alerta
+func render(tmpl *template.Template, alert alerts.Alert) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, alert); err != nil {
+		return nil, fmt.Errorf("erro ao renderizar template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// WebhookNotifier envia o alerta como JSON para uma URL HTTP
+type WebhookNotifier struct {
+	URL      string
+	Template *template.Template
+	Headers  map[string]string
+	Client   *http.Client
+}
+
+func (n *WebhookNotifier) Name() string { return "webhook" }
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert alerts.Alert) error {
+	body, err := render(n.Template, alert)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(body) {
+		return fmt.Errorf("template do webhook não gerou JSON válido")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook respondeu %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier envia o alerta por e-mail
+type SMTPNotifier struct {
+	Addr     string // host:porta
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func (n *SMTPNotifier) Name() string { return "smtp" }
+
+func (n *SMTPNotifier) Notify(ctx context.Context, alert alerts.Alert) error {
+	subject := fmt.Sprintf("[%s] %s - %s (%s)", strings.ToUpper(alert.Severity), alert.RuleName, alert.Station, alert.State)
+	body := fmt.Sprintf("Regra: %s (%s)\r\nEstação: %s\r\nEstado: %s\r\nMétrica: %s = %.2f (limiar %.2f)\r\nInício: %s\r\n",
+		alert.RuleName, alert.RuleID, alert.Station, alert.State,
+		alert.Metric, alert.Value, alert.Threshold,
+		time.Unix(alert.StartedAt, 0).UTC().Format(time.RFC3339))
+
+	msg := "From: " + n.From + "\r\n" +
+		"To: " + strings.Join(n.To, ", ") + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n" + body
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		host, _, err := net.SplitHostPort(n.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", n.Username, n.Password, host)
+	}
+
+	// net/smtp não aceita contexto; o envio roda à parte e respeita o prazo
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(n.Addr, auth, n.From, n.To, []byte(msg)) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// MQTTNotifier publica o alerta em um tópico MQTT
+type MQTTNotifier struct {
+	Topic    string
+	Template *template.Template
+	Publish  func(topic string, payload []byte) error
+}
+
+func (n *MQTTNotifier) Name() string { return "mqtt" }
+
+func (n *MQTTNotifier) Notify(ctx context.Context, alert alerts.Alert) error {
+	payload, err := render(n.Template, alert)
+	if err != nil {
+		return err
+	}
+	return n.Publish(n.Topic, payload)
+}
@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"projeto/app/alerts"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testAlert = alerts.Alert{
+	ID:        7,
+	RuleID:    "calor",
+	RuleName:  "Índice de calor",
+	Station:   "konda",
+	State:     "firing",
+	Severity:  alerts.SeverityCritical,
+	Metric:    "heat_index",
+	Value:     41.5,
+	Threshold: 40,
+	StartedAt: 1700000000,
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var body map[string]interface{}
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("corpo inválido: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	tmpl, err := ParseTemplate("default", DefaultWebhookTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := &WebhookNotifier{URL: server.URL, Template: tmpl, Headers: map[string]string{"Authorization": "Bearer segredo"}}
+	if err := n.Notify(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "Bearer segredo" {
+		t.Errorf("Authorization = %q", authorization)
+	}
+	if body["rule_id"] != "calor" || body["state"] != "firing" || body["value"] != 41.5 {
+		t.Errorf("corpo = %v", body)
+	}
+}
+
+func TestWebhookNotifierRejectsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	tmpl, _ := ParseTemplate("default", DefaultWebhookTemplate)
+	n := &WebhookNotifier{URL: server.URL, Template: tmpl}
+	if err := n.Notify(context.Background(), testAlert); err == nil {
+		t.Fatal("esperado erro com status 502")
+	}
+}
+
+// fakeSMTP atende uma sessão SMTP mínima e devolve a mensagem recebida
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 fim com .")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				messages <- data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 até logo")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().String(), messages
+}
+
+func TestSMTPNotifierEncodesSubject(t *testing.T) {
+	addr, messages := fakeSMTP(t)
+	n := &SMTPNotifier{Addr: addr, From: "estacao@example.com", To: []string{"ops@example.com"}}
+	if err := n.Notify(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+
+	var msg string
+	select {
+	case msg = <-messages:
+	case <-time.After(2 * time.Second):
+		t.Fatal("mensagem não recebida")
+	}
+
+	var subject string
+	for _, line := range strings.Split(msg, "\r\n") {
+		if strings.HasPrefix(line, "Subject: ") {
+			subject = strings.TrimPrefix(line, "Subject: ")
+		}
+	}
+	for _, r := range subject {
+		if r > 127 {
+			t.Fatalf("assunto com caracteres fora do ASCII: %q", subject)
+		}
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[CRITICAL] Índice de calor - konda (firing)"; decoded != want {
+		t.Errorf("assunto = %q, esperado %q", decoded, want)
+	}
+	if !strings.Contains(msg, "Estação: konda") {
+		t.Errorf("corpo sem a estação: %q", msg)
+	}
+}
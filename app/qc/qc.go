@@ -0,0 +1,206 @@
+package qc
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Flags guarda os indicadores de qualidade de todos os valores de uma
+// leitura: 4 bits por métrica, na ordem de Metrics
+type Flags int64
+
+// Indicadores de qualidade de um valor
+const (
+	FlagRange    Flags = 1 << iota // fora dos limites físicos (ruim)
+	FlagSpike                      // variação brusca em relação à leitura anterior (suspeito)
+	FlagFlatline                   // valor parado por tempo demais (suspeito)
+)
+
+const bitsPerMetric = 4
+
+// Métricas verificadas, na ordem dos bits em Flags
+const (
+	Temperature    = "temperature"
+	Humidity       = "humidity"
+	RainLevel      = "rain_level"
+	WindSpeed      = "average_wind_speed"
+	WindDirection  = "wind_direction"
+	UVIndex        = "uv_index"
+	SolarRadiation = "solar_radiation"
+	WindGust       = "wind_gust"
+)
+
+// Metrics lista as métricas na ordem usada em Flags
+var Metrics = []string{Temperature, Humidity, RainLevel, WindSpeed, WindDirection, UVIndex, SolarRadiation, WindGust}
+
+// Limits define as verificações de uma métrica. Zero em MaxStep ou
+// FlatlineMinutes desativa a verificação correspondente
+type Limits struct {
+	Min             float64
+	Max             float64
+	MaxStep         float64 // variação máxima por minuto
+	FlatlineMinutes float64 // tempo máximo com o mesmo valor
+}
+
+// DefaultLimits são os limites aplicados a cada métrica
+var DefaultLimits = map[string]Limits{
+	Temperature:    {Min: -40, Max: 60, MaxStep: 3, FlatlineMinutes: 60},
+	Humidity:       {Min: 0, Max: 100, MaxStep: 15, FlatlineMinutes: 360},
+	RainLevel:      {Min: 0, Max: math.Inf(1)},
+	WindSpeed:      {Min: 0, Max: 75, MaxStep: 25},
+	WindDirection:  {Min: 0, Max: 2 * math.Pi},
+	UVIndex:        {Min: 0, Max: 20, MaxStep: 6},
+	SolarRadiation: {Min: 0, Max: 1500},
+	WindGust:       {Min: 0, Max: 100, MaxStep: 40},
+}
+
+// metricIndex retorna a posição da métrica em Metrics
+func metricIndex(metric string) int {
+	for i, m := range Metrics {
+		if m == metric {
+			return i
+		}
+	}
+	return -1
+}
+
+// Set marca um indicador para a métrica
+func (f Flags) Set(metric string, flag Flags) Flags {
+	i := metricIndex(metric)
+	if i < 0 {
+		return f
+	}
+	return f | flag<<(i*bitsPerMetric)
+}
+
+// Get retorna os indicadores da métrica
+func (f Flags) Get(metric string) Flags {
+	i := metricIndex(metric)
+	if i < 0 {
+		return 0
+	}
+	return (f >> (i * bitsPerMetric)) & (1<<bitsPerMetric - 1)
+}
+
+// Names retorna os indicadores de cada métrica marcada, para exibição
+func (f Flags) Names() map[string][]string {
+	names := make(map[string][]string)
+	for _, metric := range Metrics {
+		flags := f.Get(metric)
+		if flags&FlagRange != 0 {
+			names[metric] = append(names[metric], "range")
+		}
+		if flags&FlagSpike != 0 {
+			names[metric] = append(names[metric], "spike")
+		}
+		if flags&FlagFlatline != 0 {
+			names[metric] = append(names[metric], "flatline")
+		}
+	}
+	return names
+}
+
+// Mask retorna a máscara de bits dos indicadores informados para a métrica,
+// útil para filtrar no SQL com (qc_flags & mask) = 0
+func Mask(metric string, flags Flags) Flags {
+	return Flags(0).Set(metric, flags)
+}
+
+// Filter define quais valores as consultas devem aceitar
+type Filter Flags
+
+// Níveis de qualidade aceitos no parâmetro "quality" das APIs
+const (
+	QualityAll    = "all"    // todos os valores
+	QualityUsable = "usable" // exclui valores fora dos limites físicos
+	QualityGood   = "good"   // apenas valores sem nenhum indicador
+)
+
+// ParseQuality converte o parâmetro "quality" em um filtro
+func ParseQuality(value string) (Filter, error) {
+	switch value {
+	case "", QualityAll:
+		return 0, nil
+	case QualityUsable:
+		return Filter(FlagRange), nil
+	case QualityGood:
+		return Filter(FlagRange | FlagSpike | FlagFlatline), nil
+	}
+	return 0, fmt.Errorf("qualidade inválida: %s", value)
+}
+
+// Accept indica se o valor da métrica passa pelo filtro
+func (q Filter) Accept(flags Flags, metric string) bool {
+	return flags.Get(metric)&Flags(q) == 0
+}
+
+// history é o último valor de uma métrica e desde quando ele não muda
+type history struct {
+	value     float64
+	timestamp int64
+	since     int64
+}
+
+// Checker aplica as verificações mantendo o histórico de cada estação
+type Checker struct {
+	mu     sync.Mutex
+	limits map[string]Limits
+	last   map[string]map[string]history
+}
+
+// NewChecker cria um verificador com os limites informados
+func NewChecker(limits map[string]Limits) *Checker {
+	return &Checker{limits: limits, last: make(map[string]map[string]history)}
+}
+
+var defaultChecker = NewChecker(DefaultLimits)
+
+// Check verifica os valores de uma leitura com o verificador padrão
+func Check(station string, timestamp int64, values map[string]float64) Flags {
+	return defaultChecker.Check(station, timestamp, values)
+}
+
+// Check verifica limites físicos, variação brusca e valor parado. Os
+// valores nunca são descartados, apenas marcados
+func (c *Checker) Check(station string, timestamp int64, values map[string]float64) Flags {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last, ok := c.last[station]
+	if !ok {
+		last = make(map[string]history)
+		c.last[station] = last
+	}
+
+	var flags Flags
+	for metric, value := range values {
+		limits, ok := c.limits[metric]
+		if !ok {
+			continue
+		}
+
+		if math.IsNaN(value) || value < limits.Min || value > limits.Max {
+			flags = flags.Set(metric, FlagRange)
+			// Valores impossíveis não entram no histórico
+			continue
+		}
+
+		prev, seen := last[metric]
+		current := history{value: value, timestamp: timestamp, since: timestamp}
+		if seen && timestamp > prev.timestamp {
+			minutes := math.Max(float64(timestamp-prev.timestamp)/60, 1)
+			if limits.MaxStep > 0 && math.Abs(value-prev.value)/minutes > limits.MaxStep {
+				flags = flags.Set(metric, FlagSpike)
+			}
+			if value == prev.value {
+				current.since = prev.since
+				if limits.FlatlineMinutes > 0 && float64(timestamp-prev.since)/60 > limits.FlatlineMinutes {
+					flags = flags.Set(metric, FlagFlatline)
+				}
+			}
+		}
+		last[metric] = current
+	}
+	return flags
+}
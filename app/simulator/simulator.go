@@ -0,0 +1,255 @@
+// Package simulator gera leituras SenML de estações virtuais para
+// desenvolvimento: ciclos diários de temperatura e umidade, radiação e UV,
+// eventos de chuva, vento variável e falhas configuráveis
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"projeto/app/utils"
+	"time"
+)
+
+// Faults define a frequência das falhas simuladas, como probabilidade por leitura
+type Faults struct {
+	Dropout float64 // leitura não enviada
+	Spike   float64 // uma métrica com valor absurdo
+}
+
+// Config descreve o clima das estações virtuais
+type Config struct {
+	MeanTemperature   float64 // média diária (°C)
+	TemperatureRange  float64 // amplitude entre mínima e máxima (°C)
+	MeanHumidity      float64 // %
+	MaxRadiation      float64 // W/m² ao meio-dia com céu limpo
+	MeanWind          float64 // m/s
+	RainEventsPerDay  float64
+	MaxRainRate       float64 // mm/h no pico de um evento
+	Faults            Faults
+	Seed              int64
+	TemperatureOffset []float64 // deslocamento por estação (°C), opcional
+}
+
+// DefaultConfig é um clima litorâneo subtropical
+var DefaultConfig = Config{
+	MeanTemperature:  21,
+	TemperatureRange: 9,
+	MeanHumidity:     75,
+	MaxRadiation:     950,
+	MeanWind:         3,
+	RainEventsPerDay: 0.6,
+	MaxRainRate:      25,
+	Seed:             1,
+}
+
+// Station é o estado de uma estação virtual
+type Station struct {
+	Name string
+
+	cfg    Config
+	rng    *rand.Rand
+	offset float64 // diferença de temperatura desta estação
+
+	rainLevel   float64 // contador acumulado do pluviômetro (mm)
+	rainUntil   int64   // fim do evento de chuva atual
+	rainRate    float64 // mm/h do evento atual
+	cloud       float64 // 0 = céu limpo, 1 = encoberto
+	wind        float64 // componente lenta da velocidade (m/s)
+	direction   float64 // rad
+	temperature float64 // desvio lento em relação ao ciclo (°C)
+	last        int64
+}
+
+// NewStations cria n estações com o prefixo informado ("sim" gera sim-1, sim-2...).
+// Com uma estação só, o nome é o próprio prefixo
+func NewStations(prefix string, n int, cfg Config) []*Station {
+	stations := make([]*Station, n)
+	for i := range stations {
+		name := prefix
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d", prefix, i+1)
+		}
+		s := &Station{
+			Name:      name,
+			cfg:       cfg,
+			rng:       rand.New(rand.NewSource(cfg.Seed + int64(i)*7919)),
+			direction: float64(i) * 0.7,
+			wind:      cfg.MeanWind,
+		}
+		if i < len(cfg.TemperatureOffset) {
+			s.offset = cfg.TemperatureOffset[i]
+		} else {
+			s.offset = s.rng.NormFloat64()
+		}
+		stations[i] = s
+	}
+	return stations
+}
+
+// Record é um registro SenML
+type Record struct {
+	BaseName string   `json:"bn,omitempty"`
+	BaseTime float64  `json:"bt,omitempty"`
+	Name     string   `json:"n"`
+	Unit     string   `json:"u,omitempty"`
+	Value    *float64 `json:"v"`
+}
+
+// Reading são os valores de uma leitura simulada, pelos nomes SenML
+type Reading struct {
+	Timestamp int64
+	Values    map[string]float64
+	Spiked    string // métrica com falha simulada, se houver
+}
+
+// units são as unidades SenML de cada registro
+var units = map[string]string{
+	"emw_temperature":        "Cel",
+	"emw_humidity":           "%RH",
+	"emw_rain_level":         "mm",
+	"emw_average_wind_speed": "m/s",
+	"emw_wind_gust":          "m/s",
+	"emw_wind_direction":     "rad",
+	"emw_uv":                 "/",
+	"emw_solar_radiation":    "W/m2",
+}
+
+// names fixa a ordem dos registros no pacote
+var names = []string{
+	"emw_temperature", "emw_humidity", "emw_rain_level", "emw_average_wind_speed",
+	"emw_wind_gust", "emw_wind_direction", "emw_uv", "emw_solar_radiation",
+}
+
+// Next avança a estação até o instante t e retorna a leitura. ok é falso
+// quando a falha de perda de leitura descarta esta amostra
+func (s *Station) Next(t time.Time) (Reading, bool) {
+	ts := t.Unix()
+	dt := 60.0
+	if s.last != 0 && ts > s.last {
+		dt = float64(ts - s.last)
+	}
+	s.last = ts
+	hours := dt / 3600
+
+	local := t.In(utils.Local)
+	hour := float64(local.Hour()) + float64(local.Minute())/60 + float64(local.Second())/3600
+
+	// Eventos de chuva: início aleatório, duração de 20 min a 3 h e nuvens
+	// que chegam antes e se dissipam depois
+	if ts >= s.rainUntil && s.rng.Float64() < s.cfg.RainEventsPerDay*hours/24 {
+		s.rainUntil = ts + int64(1200+s.rng.Float64()*9600)
+		s.rainRate = s.cfg.MaxRainRate * (0.1 + 0.9*s.rng.Float64()*s.rng.Float64())
+	}
+	raining := ts < s.rainUntil
+	targetCloud := 0.2
+	if raining {
+		targetCloud = 0.95
+		// Intensidade variável ao longo do evento
+		s.rainLevel += s.rainRate * hours * (0.4 + 1.2*s.rng.Float64())
+	}
+	s.cloud += (targetCloud - s.cloud) * math.Min(1, hours*2)
+	s.cloud = clamp(s.cloud+s.rng.NormFloat64()*0.02, 0, 1)
+
+	// Temperatura: mínima de madrugada (3 h) e máxima às 15 h, atenuada pelas
+	// nuvens, com um desvio lento de dia para dia
+	s.temperature += -s.temperature*0.02*hours + s.rng.NormFloat64()*0.15*math.Sqrt(hours)
+	cycle := math.Sin(2 * math.Pi * (hour - 9) / 24)
+	amplitude := s.cfg.TemperatureRange / 2 * (1 - 0.6*s.cloud)
+	temperature := s.cfg.MeanTemperature + s.offset + s.temperature + amplitude*cycle + s.rng.NormFloat64()*0.1
+
+	// Umidade relativa acompanha o ciclo ao contrário e satura com chuva
+	humidity := s.cfg.MeanHumidity - 3*amplitude*cycle + 15*s.cloud - 5 + s.rng.NormFloat64()
+	if raining {
+		humidity = 92 + 6*s.rng.Float64()
+	}
+	humidity = clamp(humidity, 15, 100)
+
+	// Radiação solar entre 6 h e 18 h, reduzida pelas nuvens; UV proporcional
+	radiation := 0.0
+	if elevation := math.Sin(math.Pi * (hour - 6) / 12); elevation > 0 {
+		radiation = s.cfg.MaxRadiation * math.Pow(elevation, 1.2) * (1 - 0.75*s.cloud)
+		radiation = math.Max(0, radiation+s.rng.NormFloat64()*10)
+	}
+	uv := math.Round(radiation/90*10) / 10
+
+	// Vento: componente lenta que reverte à média, mais forte à tarde e com
+	// chuva, rajadas de 30% a 90% acima da média e direção em passeio aleatório
+	target := s.cfg.MeanWind * (1 + 0.4*math.Sin(2*math.Pi*(hour-10)/24))
+	if raining {
+		target *= 1.6
+	}
+	s.wind += (target-s.wind)*math.Min(1, hours*3) + s.rng.NormFloat64()*0.3*math.Sqrt(hours*60)
+	s.wind = math.Max(0, s.wind)
+	wind := math.Max(0, s.wind+s.rng.NormFloat64()*0.3)
+	gust := wind * (1.3 + 0.6*s.rng.Float64())
+	s.direction = math.Mod(s.direction+s.rng.NormFloat64()*0.15*math.Sqrt(hours*60)+2*math.Pi, 2*math.Pi)
+
+	reading := Reading{Timestamp: ts, Values: map[string]float64{
+		"emw_temperature":        round(temperature, 2),
+		"emw_humidity":           round(humidity, 1),
+		"emw_rain_level":         round(s.rainLevel, 2),
+		"emw_average_wind_speed": round(wind, 2),
+		"emw_wind_gust":          round(gust, 2),
+		"emw_wind_direction":     round(s.direction, 3),
+		"emw_uv":                 uv,
+		"emw_solar_radiation":    round(radiation, 1),
+	}}
+
+	if s.rng.Float64() < s.cfg.Faults.Dropout {
+		return reading, false
+	}
+	if s.rng.Float64() < s.cfg.Faults.Spike {
+		reading.Spiked = spike(s.rng, reading.Values)
+	}
+	return reading, true
+}
+
+// spike troca o valor de uma métrica por um valor absurdo, como um sensor
+// com defeito ou ruído na transmissão
+func spike(rng *rand.Rand, values map[string]float64) string {
+	name := names[rng.Intn(len(names))]
+	switch name {
+	case "emw_rain_level":
+		// Contador que reinicia
+		values[name] = 0
+	case "emw_humidity":
+		values[name] = 150 + rng.Float64()*50
+	default:
+		sign := 1.0
+		if rng.Intn(2) == 0 {
+			sign = -1
+		}
+		values[name] = values[name] + sign*(40+rng.Float64()*60)
+	}
+	return name
+}
+
+// Payload monta o pacote SenML da leitura, com nome e tempo base
+func (s *Station) Payload(r Reading) ([]byte, error) {
+	records := make([]Record, 0, len(names))
+	for _, name := range names {
+		value, ok := r.Values[name]
+		if !ok {
+			continue
+		}
+		v := value
+		record := Record{Name: name, Unit: units[name], Value: &v}
+		if len(records) == 0 {
+			record.BaseName = s.Name
+			record.BaseTime = float64(r.Timestamp)
+		}
+		records = append(records, record)
+	}
+	return json.Marshal(records)
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
+
+func round(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
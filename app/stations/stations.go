@@ -0,0 +1,165 @@
+package stations
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"projeto/app/alerts"
+	"projeto/app/config"
+	"strings"
+	"time"
+)
+
+// OfflineRuleID é o id da regra de alerta de estação sem dados
+const OfflineRuleID = "estacao-offline"
+
+// AgeMetric é a métrica com os segundos desde a última leitura da estação
+const AgeMetric = "data_age_seconds"
+
+// Station é o estado de recepção de uma estação
+type Station struct {
+	ID       string `json:"id"`
+	LastSeen int64  `json:"last_seen"`
+	Age      int64  `json:"data_age_seconds"`
+	Stale    bool   `json:"stale"`
+}
+
+var staleAfter = 10 * time.Minute
+
+// ignored são os padrões (path.Match) das estações aposentadas ou de teste,
+// que não são listadas nem vigiadas
+var ignored []string
+
+// Ignored indica se a estação está na lista STATION_IGNORE
+func Ignored(station string) bool {
+	for _, pattern := range ignored {
+		if ok, _ := path.Match(pattern, station); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// StaleAfter retorna o intervalo sem leituras após o qual os dados de uma
+// estação são considerados desatualizados
+func StaleAfter() time.Duration {
+	return staleAfter
+}
+
+// IsStale indica se uma leitura feita em lastSeen está desatualizada em now
+func IsStale(lastSeen int64, now time.Time) bool {
+	return lastSeen == 0 || now.Sub(time.Unix(lastSeen, 0)) > staleAfter
+}
+
+// Setup lê STATION_STALE_AFTER (padrão 10m) e STATION_IGNORE (padrões
+// separados por vírgula, como "antiga,sim-*"), registra a regra de alerta
+// de estação sem dados e inicia a verificação periódica
+func Setup(engine *alerts.Engine) {
+	for _, pattern := range strings.Split(os.Getenv("STATION_IGNORE"), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			slog.Warn("Padrão inválido em STATION_IGNORE", "pattern", pattern, "err", err)
+			continue
+		}
+		ignored = append(ignored, pattern)
+	}
+	if value := os.Getenv("STATION_STALE_AFTER"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			slog.Warn("STATION_STALE_AFTER inválido, usando padrão", "value", value, "default", staleAfter)
+		} else {
+			staleAfter = d
+		}
+	}
+
+	rule, err := alerts.NewRule(OfflineRuleID, "Estação sem dados",
+		fmt.Sprintf("%s > %d", AgeMetric, int64(staleAfter.Seconds())), alerts.SeverityCritical)
+	if err != nil {
+		slog.Error("Erro ao criar regra de estação sem dados", "err", err)
+		return
+	}
+	engine.AddRule(rule)
+
+	go watch(engine, time.Minute)
+}
+
+// watch avalia periodicamente a idade dos dados de cada estação, para que
+// o alerta dispare mesmo sem novas leituras
+func watch(engine *alerts.Engine, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		db, err := sql.Open("mysql", config.DatabaseDSN())
+		if err != nil {
+			slog.Error("Erro ao conectar ao banco", "err", err)
+			continue
+		}
+
+		now := time.Now()
+		list, err := load(db, now)
+		if err != nil {
+			slog.Error("Erro ao listar estações", "err", err)
+		}
+		for _, s := range list {
+			// Uma estação ignorada é avaliada com idade zero para resolver
+			// o alerta que estivesse aberto quando entrou na lista
+			if Ignored(s.ID) {
+				engine.Evaluate(db, s.ID, now.Unix(), map[string]float64{AgeMetric: 0})
+				continue
+			}
+			if s.Stale {
+				slog.Warn("Estação sem dados", "station", s.ID, "last_seen", s.LastSeen, "age", s.Age)
+			}
+			engine.Evaluate(db, s.ID, now.Unix(), map[string]float64{AgeMetric: float64(s.Age)})
+		}
+		db.Close()
+	}
+}
+
+// Touch registra a recepção de uma leitura da estação
+func Touch(db *sql.DB, station string, timestamp int64) error {
+	_, err := db.Exec(`
+        INSERT INTO stations (id, first_seen, last_seen) VALUES (?, ?, ?)
+        ON DUPLICATE KEY UPDATE last_seen = GREATEST(last_seen, VALUES(last_seen))
+    `, station, timestamp, timestamp)
+	return err
+}
+
+// List retorna as estações conhecidas com a idade dos dados em now, exceto
+// as ignoradas
+func List(db *sql.DB, now time.Time) ([]Station, error) {
+	all, err := load(db, now)
+	list := []Station{}
+	for _, s := range all {
+		if !Ignored(s.ID) {
+			list = append(list, s)
+		}
+	}
+	return list, err
+}
+
+// load retorna todas as estações da tabela, inclusive as ignoradas
+func load(db *sql.DB, now time.Time) ([]Station, error) {
+	rows, err := db.Query("SELECT id, last_seen FROM stations ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Station{}
+	for rows.Next() {
+		var s Station
+		if err := rows.Scan(&s.ID, &s.LastSeen); err != nil {
+			return nil, err
+		}
+		s.Age = now.Unix() - s.LastSeen
+		s.Stale = IsStale(s.LastSeen, now)
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
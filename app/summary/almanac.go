@@ -0,0 +1,204 @@
+package summary
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// Records são os recordes de um escopo (todo o histórico ou um mês do ano)
+type Records struct {
+	HighestTemp   *Extreme `json:"highest_temperature"`
+	LowestTemp    *Extreme `json:"lowest_temperature"`
+	WettestDay    *Extreme `json:"wettest_day"`
+	StrongestWind *Extreme `json:"strongest_wind"`
+	HighestUV     *Extreme `json:"highest_uv"`
+}
+
+// MonthRecords são os recordes de um mês do calendário em todos os anos
+type MonthRecords struct {
+	Month   int     `json:"month"`
+	Records Records `json:"records"`
+}
+
+// Almanac reúne os recordes de todo o histórico e de cada mês
+type Almanac struct {
+	AllTime Records        `json:"all_time"`
+	Months  []MonthRecords `json:"months"`
+}
+
+// RecordFlag indica que a leitura atual bate ou iguala um recorde
+type RecordFlag struct {
+	Record   string   `json:"record"`
+	Scope    string   `json:"scope"`  // "all_time" ou "month"
+	Status   string   `json:"status"` // "new" ou "tied"
+	Value    float64  `json:"value"`
+	Previous *Extreme `json:"previous"`
+}
+
+// add incorpora um dia aos recordes. Em caso de empate prevalece a
+// ocorrência mais antiga, pois os dias chegam em ordem cronológica
+func (r *Records) add(d DailySummary) {
+	updateExtreme(&r.HighestTemp, d.TempMax, d.Date, greater)
+	updateExtreme(&r.LowestTemp, d.TempMin, d.Date, less)
+	updateExtreme(&r.StrongestWind, d.WindGustMax, d.Date, greater)
+	updateExtreme(&r.HighestUV, d.UVMax, d.Date, greater)
+	if d.RainTotal > 0 {
+		rain := d.RainTotal
+		updateExtreme(&r.WettestDay, &rain, d.Date, greater)
+	}
+}
+
+// BuildAlmanac calcula os recordes a partir dos resumos diários em ordem cronológica
+func BuildAlmanac(days []DailySummary) Almanac {
+	var almanac Almanac
+	months := make([]Records, 12)
+	for _, d := range days {
+		date, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			continue
+		}
+		almanac.AllTime.add(d)
+		months[date.Month()-1].add(d)
+	}
+	for i, records := range months {
+		almanac.Months = append(almanac.Months, MonthRecords{Month: i + 1, Records: records})
+	}
+	return almanac
+}
+
+// almanacKey identifica um almanaque guardado em cache
+type almanacKey struct {
+	station string
+	before  string
+}
+
+// almanacs guarda os almanaques já calculados, para que a consulta da leitura
+// atual não releia todo o daily_summary a cada atualização da página
+var (
+	almanacMu sync.Mutex
+	almanacs  = map[almanacKey]Almanac{}
+)
+
+// invalidateAlmanac descarta os almanaques da estação que incluem o dia
+// (AAAA-MM-DD) recém-gravado
+func invalidateAlmanac(station, date string) {
+	almanacMu.Lock()
+	defer almanacMu.Unlock()
+	for key := range almanacs {
+		if key.station == station && (key.before == "" || date < key.before) {
+			delete(almanacs, key)
+		}
+	}
+}
+
+// storeAlmanac guarda o almanaque e descarta os de dias anteriores da mesma
+// estação, que não serão mais pedidos
+func storeAlmanac(key almanacKey, almanac Almanac) {
+	almanacMu.Lock()
+	defer almanacMu.Unlock()
+	if key.before != "" {
+		for other := range almanacs {
+			if other.station == key.station && other.before != "" && other.before < key.before {
+				delete(almanacs, other)
+			}
+		}
+	}
+	almanacs[key] = almanac
+}
+
+// LoadAlmanac calcula os recordes da estação com todos os dias anteriores a
+// before (AAAA-MM-DD), ou com todo o histórico se before for vazio. O
+// resultado fica em cache até que SaveDay grave um dia incluído nele e não
+// deve ser modificado
+func LoadAlmanac(db *sql.DB, station, before string) (Almanac, error) {
+	key := almanacKey{station: station, before: before}
+	almanacMu.Lock()
+	almanac, ok := almanacs[key]
+	almanacMu.Unlock()
+	if ok {
+		return almanac, nil
+	}
+
+	to := "9999-12-31"
+	if before != "" {
+		date, err := time.Parse("2006-01-02", before)
+		if err != nil {
+			return Almanac{}, err
+		}
+		to = date.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	days, err := LoadRange(db, station, "0001-01-01", to)
+	if err != nil {
+		return Almanac{}, err
+	}
+	almanac = BuildAlmanac(days)
+	storeAlmanac(key, almanac)
+	return almanac, nil
+}
+
+// compareRecord retorna o status da comparação do valor com o recorde
+func compareRecord(value float64, record *Extreme, better func(a, b float64) bool) string {
+	switch {
+	case record == nil:
+		return ""
+	case better(value, record.Value):
+		return "new"
+	case value == record.Value:
+		return "tied"
+	default:
+		return ""
+	}
+}
+
+// CurrentReading são os valores atuais comparados com os recordes
+type CurrentReading struct {
+	Temperature float64
+	WindGust    float64 // m/s, comparada com a maior rajada do dia
+	UVIndex     float64
+	RainToday   float64 // mm
+}
+
+// CheckRecords compara a leitura atual com os recordes de todo o histórico
+// e do mês corrente
+func CheckRecords(almanac Almanac, month int, current CurrentReading) []RecordFlag {
+	flags := []RecordFlag{}
+	scopes := map[string]Records{"all_time": almanac.AllTime}
+	if month >= 1 && month <= len(almanac.Months) {
+		scopes["month"] = almanac.Months[month-1].Records
+	}
+
+	for _, scope := range []string{"all_time", "month"} {
+		records, ok := scopes[scope]
+		if !ok {
+			continue
+		}
+		checks := []struct {
+			name   string
+			value  float64
+			record *Extreme
+			better func(a, b float64) bool
+		}{
+			{"highest_temperature", current.Temperature, records.HighestTemp, greater},
+			{"lowest_temperature", current.Temperature, records.LowestTemp, less},
+			{"strongest_wind", current.WindGust, records.StrongestWind, greater},
+			{"highest_uv", current.UVIndex, records.HighestUV, greater},
+			{"wettest_day", current.RainToday, records.WettestDay, greater},
+		}
+		for _, c := range checks {
+			if c.name == "wettest_day" && c.value <= 0 {
+				continue
+			}
+			if status := compareRecord(c.value, c.record, c.better); status != "" {
+				flags = append(flags, RecordFlag{
+					Record:   c.name,
+					Scope:    scope,
+					Status:   status,
+					Value:    c.value,
+					Previous: c.record,
+				})
+			}
+		}
+	}
+	return flags
+}
@@ -0,0 +1,257 @@
+package summary
+
+import (
+	"fmt"
+	"math"
+	"projeto/app/utils"
+	"strings"
+	"time"
+)
+
+// degreeDayBase é a base de graus-dia de aquecimento/resfriamento (65 °F)
+const degreeDayBase = 18.3
+
+// Extreme é um valor extremo com a data em que ocorreu
+type Extreme struct {
+	Value float64 `json:"value"`
+	Date  string  `json:"date"`
+}
+
+// Totals resume um conjunto de dias no formato dos relatórios do NOAA
+type Totals struct {
+	Days           int      `json:"days"`
+	MeanMax        *float64 `json:"mean_max"`
+	MeanMin        *float64 `json:"mean_min"`
+	Mean           *float64 `json:"mean"`
+	Highest        *Extreme `json:"highest"`
+	Lowest         *Extreme `json:"lowest"`
+	HeatingDegDays float64  `json:"heating_degree_days"`
+	CoolingDegDays float64  `json:"cooling_degree_days"`
+	RainTotal      float64  `json:"rain_total"`
+	MaxDailyRain   *Extreme `json:"max_daily_rain"`
+	RainDays02     int      `json:"rain_days_0_2mm"`
+	RainDays2      int      `json:"rain_days_2mm"`
+	RainDays20     int      `json:"rain_days_20mm"`
+	MaxGust        *Extreme `json:"max_gust"`
+	MaxUV          *Extreme `json:"max_uv"`
+	DaysMaxGE32    int      `json:"days_max_ge_32"`
+	DaysMaxLE0     int      `json:"days_max_le_0"`
+	DaysMinLE0     int      `json:"days_min_le_0"`
+	RadiationTotal float64  `json:"radiation_total"`
+}
+
+// MonthlyReport é o relatório climatológico de um mês
+type MonthlyReport struct {
+	Year   int            `json:"year"`
+	Month  int            `json:"month"`
+	Days   []DailySummary `json:"days"`
+	Totals Totals         `json:"totals"`
+}
+
+// MonthTotals é a linha de um mês no relatório anual
+type MonthTotals struct {
+	Month  int    `json:"month"`
+	Totals Totals `json:"totals"`
+}
+
+// AnnualReport é o relatório climatológico de um ano
+type AnnualReport struct {
+	Year   int           `json:"year"`
+	Months []MonthTotals `json:"months"`
+	Totals Totals        `json:"totals"`
+}
+
+// updateExtreme substitui o extremo quando o valor o supera segundo better
+func updateExtreme(current **Extreme, value *float64, date string, better func(a, b float64) bool) {
+	if value == nil {
+		return
+	}
+	if *current == nil || better(*value, (*current).Value) {
+		*current = &Extreme{Value: *value, Date: date}
+	}
+}
+
+func greater(a, b float64) bool { return a > b }
+func less(a, b float64) bool    { return a < b }
+
+// mean retorna a média ou nil se não houver valores
+func mean(sum float64, count int) *float64 {
+	if count == 0 {
+		return nil
+	}
+	m := sum / float64(count)
+	return &m
+}
+
+// Summarize calcula os totais de um conjunto de resumos diários
+func Summarize(days []DailySummary) Totals {
+	t := Totals{Days: len(days)}
+	var sumMax, sumMin, sumMean float64
+	var countMax, countMin, countMean int
+
+	for _, d := range days {
+		if d.TempMax != nil {
+			sumMax += *d.TempMax
+			countMax++
+			if *d.TempMax >= 32 {
+				t.DaysMaxGE32++
+			}
+			if *d.TempMax <= 0 {
+				t.DaysMaxLE0++
+			}
+		}
+		if d.TempMin != nil {
+			sumMin += *d.TempMin
+			countMin++
+			if *d.TempMin <= 0 {
+				t.DaysMinLE0++
+			}
+		}
+		if d.TempMax != nil && d.TempMin != nil {
+			dayMean := (*d.TempMax + *d.TempMin) / 2
+			sumMean += dayMean
+			countMean++
+			t.HeatingDegDays += math.Max(0, degreeDayBase-dayMean)
+			t.CoolingDegDays += math.Max(0, dayMean-degreeDayBase)
+		}
+		updateExtreme(&t.Highest, d.TempMax, d.Date, greater)
+		updateExtreme(&t.Lowest, d.TempMin, d.Date, less)
+		updateExtreme(&t.MaxGust, d.WindGustMax, d.Date, greater)
+		updateExtreme(&t.MaxUV, d.UVMax, d.Date, greater)
+
+		rain := d.RainTotal
+		t.RainTotal += rain
+		updateExtreme(&t.MaxDailyRain, &rain, d.Date, greater)
+		if rain >= 0.2 {
+			t.RainDays02++
+		}
+		if rain >= 2 {
+			t.RainDays2++
+		}
+		if rain >= 20 {
+			t.RainDays20++
+		}
+		if d.RadiationEnergy != nil {
+			t.RadiationTotal += *d.RadiationEnergy
+		}
+	}
+
+	t.MeanMax = mean(sumMax, countMax)
+	t.MeanMin = mean(sumMin, countMin)
+	t.Mean = mean(sumMean, countMean)
+	return t
+}
+
+// BuildMonthlyReport monta o relatório do mês a partir dos resumos diários
+func BuildMonthlyReport(year, month int, days []DailySummary) MonthlyReport {
+	return MonthlyReport{Year: year, Month: month, Days: days, Totals: Summarize(days)}
+}
+
+// BuildAnnualReport monta o relatório do ano a partir dos resumos diários
+func BuildAnnualReport(year int, days []DailySummary) AnnualReport {
+	report := AnnualReport{Year: year, Totals: Summarize(days)}
+	byMonth := make(map[int][]DailySummary)
+	for _, d := range days {
+		date, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			continue
+		}
+		byMonth[int(date.Month())] = append(byMonth[int(date.Month())], d)
+	}
+	for month := 1; month <= 12; month++ {
+		if len(byMonth[month]) == 0 {
+			continue
+		}
+		report.Months = append(report.Months, MonthTotals{Month: month, Totals: Summarize(byMonth[month])})
+	}
+	return report
+}
+
+// fmtValue formata um valor opcional com largura fixa
+func fmtValue(v *float64, width, precision int) string {
+	if v == nil {
+		return fmt.Sprintf("%*s", width, "---")
+	}
+	return fmt.Sprintf("%*.*f", width, precision, *v)
+}
+
+// fmtTime formata o horário local (HH:MM) de um timestamp opcional
+func fmtTime(ts *int64) string {
+	if ts == nil {
+		return "  --- "
+	}
+	return " " + time.Unix(*ts, 0).In(utils.Local).Format("15:04")
+}
+
+// fmtExtreme formata um extremo como "valor (dia)"
+func fmtExtreme(e *Extreme, precision int) string {
+	if e == nil {
+		return "---"
+	}
+	return fmt.Sprintf("%.*f em %s", precision, e.Value, e.Date)
+}
+
+// writeTotals escreve o bloco de totais comum aos relatórios em texto
+func writeTotals(b *strings.Builder, t Totals) {
+	fmt.Fprintf(b, "Média das máximas: %s °C    Média das mínimas: %s °C    Média: %s °C\n",
+		fmtValue(t.MeanMax, 0, 1), fmtValue(t.MeanMin, 0, 1), fmtValue(t.Mean, 0, 1))
+	fmt.Fprintf(b, "Maior temperatura: %s    Menor temperatura: %s\n",
+		fmtExtreme(t.Highest, 1), fmtExtreme(t.Lowest, 1))
+	fmt.Fprintf(b, "Dias com máx >= 32 °C: %d    máx <= 0 °C: %d    mín <= 0 °C: %d\n",
+		t.DaysMaxGE32, t.DaysMaxLE0, t.DaysMinLE0)
+	fmt.Fprintf(b, "Graus-dia de aquecimento: %.1f    de resfriamento: %.1f (base %.1f °C)\n",
+		t.HeatingDegDays, t.CoolingDegDays, degreeDayBase)
+	fmt.Fprintf(b, "Chuva total: %.1f mm    Maior chuva diária: %s\n", t.RainTotal, fmtExtreme(t.MaxDailyRain, 1))
+	fmt.Fprintf(b, "Dias com chuva >= 0,2 mm: %d    >= 2 mm: %d    >= 20 mm: %d\n",
+		t.RainDays02, t.RainDays2, t.RainDays20)
+	fmt.Fprintf(b, "Rajada máxima (m/s): %s    UV máximo: %s\n", fmtExtreme(t.MaxGust, 1), fmtExtreme(t.MaxUV, 0))
+	fmt.Fprintf(b, "Radiação solar total: %.1f MJ/m²\n", t.RadiationTotal)
+}
+
+// FormatMonthlyText gera o relatório mensal em texto de largura fixa
+func FormatMonthlyText(r MonthlyReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "RESUMO CLIMATOLÓGICO MENSAL - %02d/%d\n\n", r.Month, r.Year)
+	b.WriteString("                MÉDIA                                            RAJADA\n")
+	b.WriteString("DIA    TEMP   UMID   MÁX  HORA    MÍN  HORA   CHUVA   RAJ  HORA   UV   RAD\n")
+	b.WriteString(strings.Repeat("-", 76) + "\n")
+	for _, d := range r.Days {
+		fmt.Fprintf(&b, "%s %s %s %s%s %s%s %s %s%s %s %s\n",
+			d.Date[8:], fmtValue(d.TempAvg, 7, 1), fmtValue(d.HumidityAvg, 6, 0),
+			fmtValue(d.TempMax, 6, 1), fmtTime(d.TempMaxTime),
+			fmtValue(d.TempMin, 6, 1), fmtTime(d.TempMinTime),
+			fmtValue(&d.RainTotal, 7, 1), fmtValue(d.WindGustMax, 5, 1), fmtTime(d.WindGustMaxTime),
+			fmtValue(d.UVMax, 4, 0), fmtValue(d.RadiationEnergy, 5, 1))
+	}
+	b.WriteString(strings.Repeat("-", 76) + "\n\n")
+	writeTotals(&b, r.Totals)
+	return b.String()
+}
+
+// FormatAnnualText gera o relatório anual em texto de largura fixa
+func FormatAnnualText(r AnnualReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "RESUMO CLIMATOLÓGICO ANUAL - %d\n\n", r.Year)
+	b.WriteString("MÊS  MÉD MÁX  MÉD MÍN   MÉDIA   MAIOR   MENOR    CHUVA  DIAS>=0,2  RAJADA\n")
+	b.WriteString(strings.Repeat("-", 76) + "\n")
+	for _, m := range r.Months {
+		t := m.Totals
+		var highest, lowest, gust *float64
+		if t.Highest != nil {
+			highest = &t.Highest.Value
+		}
+		if t.Lowest != nil {
+			lowest = &t.Lowest.Value
+		}
+		if t.MaxGust != nil {
+			gust = &t.MaxGust.Value
+		}
+		fmt.Fprintf(&b, " %02d %s %s %s %s %s %s %10d %s\n",
+			m.Month, fmtValue(t.MeanMax, 8, 1), fmtValue(t.MeanMin, 8, 1), fmtValue(t.Mean, 7, 1),
+			fmtValue(highest, 7, 1), fmtValue(lowest, 7, 1), fmtValue(&t.RainTotal, 8, 1),
+			t.RainDays02, fmtValue(gust, 7, 1))
+	}
+	b.WriteString(strings.Repeat("-", 76) + "\n\n")
+	writeTotals(&b, r.Totals)
+	return b.String()
+}
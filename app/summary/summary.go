@@ -0,0 +1,419 @@
+package summary
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"projeto/app/meteorology"
+	"projeto/app/qc"
+	"projeto/app/utils"
+	"time"
+)
+
+// maxRadiationGap limita o intervalo entre leituras usado na integração da
+// radiação solar, para que falhas de transmissão não inflem a energia
+const maxRadiationGap = 30 * 60
+
+// DailySummary é o resumo climatológico de um dia local de uma estação.
+// Campos nulos indicam que o sensor correspondente não enviou dados no dia
+type DailySummary struct {
+	Station         string   `json:"station"`
+	Date            string   `json:"date"`
+	TempMin         *float64 `json:"temperature_min"`
+	TempMinTime     *int64   `json:"temperature_min_time"`
+	TempMax         *float64 `json:"temperature_max"`
+	TempMaxTime     *int64   `json:"temperature_max_time"`
+	TempAvg         *float64 `json:"temperature_avg"`
+	HumidityAvg     *float64 `json:"humidity_avg"`
+	RainTotal       float64  `json:"rain_total"`
+	WindGustMax     *float64 `json:"wind_gust_max"`
+	WindGustMaxTime *int64   `json:"wind_gust_max_time"`
+	UVMax           *float64 `json:"uv_max"`
+	RadiationEnergy *float64 `json:"radiation_energy"`
+	Samples         int      `json:"samples"`
+
+	// Estado da atualização incremental, gravado junto com o resumo
+	tempSum, humiditySum     float64
+	tempCount, humidityCount int
+	lastTimestamp            int64 // última leitura incorporada
+}
+
+// DayStart retorna o início do dia local que contém o timestamp
+func DayStart(timestamp int64) time.Time {
+	t := time.Unix(timestamp, 0).In(utils.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, utils.Local)
+}
+
+// usable define os valores aceitos nos resumos: os marcados fora dos limites
+// físicos são descartados
+const usable = qc.Filter(qc.FlagRange)
+
+// ComputeDay calcula o resumo da estação no dia local iniciado em day a
+// partir de sensor_data
+func ComputeDay(db *sql.DB, station string, day time.Time) (DailySummary, error) {
+	start := day.Unix()
+	end := day.AddDate(0, 0, 1).Unix() - 1
+	summary := DailySummary{Station: station, Date: day.Format("2006-01-02")}
+
+	rows, err := db.Query(`
+        SELECT timestamp, temperature, humidity, rain_level,
+               average_wind_speed, wind_gust, uv_index, solar_radiation, qc_flags
+        FROM sensor_data
+        WHERE station = ? AND timestamp BETWEEN ? AND ?
+        ORDER BY timestamp
+    `, station, start, end)
+	if err != nil {
+		return summary, fmt.Errorf("erro ao buscar leituras: %w", err)
+	}
+	defer rows.Close()
+
+	// A última leitura do pluviômetro antes do dia entra na diferença inicial
+	var rain []meteorology.RainSample
+	var previous sql.NullFloat64
+	var previousTimestamp int64
+	err = db.QueryRow(`
+        SELECT timestamp, rain_level FROM sensor_data
+        WHERE station = ? AND timestamp < ? AND rain_level IS NOT NULL
+          AND (qc_flags & ?) = 0
+        ORDER BY timestamp DESC LIMIT 1
+    `, station, start, qc.Mask(qc.RainLevel, qc.FlagRange)).Scan(&previousTimestamp, &previous)
+	if err == nil && previous.Valid {
+		rain = append(rain, meteorology.RainSample{Timestamp: previousTimestamp, Level: previous.Float64})
+	}
+
+	var tempSum, humiditySum, energy float64
+	var tempCount, humidityCount int
+	var lastRadiation sql.NullFloat64
+	var lastRadiationTime int64
+
+	for rows.Next() {
+		var timestamp int64
+		var temp, humidity, rainLevel, wind, gust, uv, radiation sql.NullFloat64
+		var flags qc.Flags
+		if err := rows.Scan(&timestamp, &temp, &humidity, &rainLevel, &wind, &gust, &uv, &radiation, &flags); err != nil {
+			return summary, fmt.Errorf("erro ao ler leitura: %w", err)
+		}
+		summary.Samples++
+		summary.lastTimestamp = timestamp
+
+		// Descarta os valores marcados pelo controle de qualidade
+		for metric, value := range map[string]*sql.NullFloat64{
+			qc.Temperature: &temp, qc.Humidity: &humidity, qc.RainLevel: &rainLevel,
+			qc.WindSpeed: &wind, qc.WindGust: &gust, qc.UVIndex: &uv, qc.SolarRadiation: &radiation,
+		} {
+			if !usable.Accept(flags, metric) {
+				value.Valid = false
+			}
+		}
+		ts := timestamp
+
+		if temp.Valid {
+			v := temp.Float64
+			tempSum += v
+			tempCount++
+			if summary.TempMin == nil || v < *summary.TempMin {
+				summary.TempMin, summary.TempMinTime = &v, &ts
+			}
+			if summary.TempMax == nil || v > *summary.TempMax {
+				summary.TempMax, summary.TempMaxTime = &v, &ts
+			}
+		}
+		if humidity.Valid {
+			humiditySum += humidity.Float64
+			humidityCount++
+		}
+		if rainLevel.Valid {
+			rain = append(rain, meteorology.RainSample{Timestamp: timestamp, Level: rainLevel.Float64})
+		}
+		// A rajada máxima usa a rajada informada pela estação e, na falta
+		// dela, a velocidade média
+		if gust.Valid && (!wind.Valid || gust.Float64 > wind.Float64) {
+			wind = gust
+		}
+		if wind.Valid && (summary.WindGustMax == nil || wind.Float64 > *summary.WindGustMax) {
+			v := wind.Float64
+			summary.WindGustMax, summary.WindGustMaxTime = &v, &ts
+		}
+		if uv.Valid && (summary.UVMax == nil || uv.Float64 > *summary.UVMax) {
+			v := uv.Float64
+			summary.UVMax = &v
+		}
+		if radiation.Valid {
+			// Integração trapezoidal da potência (W/m²) no tempo
+			if lastRadiation.Valid && timestamp-lastRadiationTime <= maxRadiationGap {
+				energy += (radiation.Float64 + lastRadiation.Float64) / 2 * float64(timestamp-lastRadiationTime)
+			}
+			lastRadiation, lastRadiationTime = radiation, timestamp
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return summary, fmt.Errorf("erro ao ler leituras: %w", err)
+	}
+
+	summary.tempSum, summary.tempCount = tempSum, tempCount
+	summary.humiditySum, summary.humidityCount = humiditySum, humidityCount
+	if tempCount > 0 {
+		avg := tempSum / float64(tempCount)
+		summary.TempAvg = &avg
+	}
+	if humidityCount > 0 {
+		avg := humiditySum / float64(humidityCount)
+		summary.HumidityAvg = &avg
+	}
+	if lastRadiation.Valid {
+		mj := energy / 1e6
+		summary.RadiationEnergy = &mj
+	}
+	increments, _ := meteorology.RainIncrements(rain)
+	for _, inc := range increments {
+		summary.RainTotal += inc
+	}
+
+	return summary, nil
+}
+
+// SaveDay grava (ou substitui) o resumo do dia da estação em daily_summary e
+// descarta os almanaques em cache que incluem o dia
+func SaveDay(db *sql.DB, s DailySummary) error {
+	defer invalidateAlmanac(s.Station, s.Date)
+	_, err := db.Exec(`
+        INSERT INTO daily_summary (
+            station, date, temp_min, temp_min_time, temp_max, temp_max_time, temp_avg,
+            humidity_avg, rain_total, wind_gust_max, wind_gust_max_time,
+            uv_max, radiation_energy, samples, temp_sum, temp_count,
+            humidity_sum, humidity_count, last_timestamp, updated_at
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        ON DUPLICATE KEY UPDATE
+            temp_min=VALUES(temp_min),
+            temp_min_time=VALUES(temp_min_time),
+            temp_max=VALUES(temp_max),
+            temp_max_time=VALUES(temp_max_time),
+            temp_avg=VALUES(temp_avg),
+            humidity_avg=VALUES(humidity_avg),
+            rain_total=VALUES(rain_total),
+            wind_gust_max=VALUES(wind_gust_max),
+            wind_gust_max_time=VALUES(wind_gust_max_time),
+            uv_max=VALUES(uv_max),
+            radiation_energy=VALUES(radiation_energy),
+            samples=VALUES(samples),
+            temp_sum=VALUES(temp_sum),
+            temp_count=VALUES(temp_count),
+            humidity_sum=VALUES(humidity_sum),
+            humidity_count=VALUES(humidity_count),
+            last_timestamp=VALUES(last_timestamp),
+            updated_at=VALUES(updated_at)
+    `, s.Station, s.Date, s.TempMin, s.TempMinTime, s.TempMax, s.TempMaxTime, s.TempAvg,
+		s.HumidityAvg, s.RainTotal, s.WindGustMax, s.WindGustMaxTime,
+		s.UVMax, s.RadiationEnergy, s.Samples, s.tempSum, s.tempCount,
+		s.humiditySum, s.humidityCount, s.lastTimestamp, time.Now().Unix())
+	return err
+}
+
+// AddReading incorpora uma leitura recém-gravada ao resumo do dia da
+// estação sem reler o dia: extremos, somas e contagens recebem os valores
+// aceitos e a chuva e a radiação o trecho desde a leitura anterior. Leituras
+// fora de ordem ou regravadas, e dias sem o estado incremental, levam ao
+// recálculo completo. É chamado pela ingestão ao vivo; cargas históricas
+// usam Backfill
+func AddReading(db *sql.DB, station string, timestamp int64, values map[string]float64, flags qc.Flags) error {
+	day := DayStart(timestamp)
+	s, found, err := loadDay(db, station, day.Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	if !found || s.lastTimestamp == 0 || timestamp <= s.lastTimestamp {
+		if s, err = ComputeDay(db, station, day); err != nil {
+			return err
+		}
+		return SaveDay(db, s)
+	}
+
+	value := func(metric string) (float64, bool) {
+		v, ok := values[metric]
+		return v, ok && usable.Accept(flags, metric)
+	}
+	ts := timestamp
+	s.Samples++
+	s.lastTimestamp = timestamp
+
+	if v, ok := value(qc.Temperature); ok {
+		s.tempSum += v
+		s.tempCount++
+		avg := s.tempSum / float64(s.tempCount)
+		s.TempAvg = &avg
+		if s.TempMin == nil || v < *s.TempMin {
+			s.TempMin, s.TempMinTime = &v, &ts
+		}
+		if s.TempMax == nil || v > *s.TempMax {
+			s.TempMax, s.TempMaxTime = &v, &ts
+		}
+	}
+	if v, ok := value(qc.Humidity); ok {
+		s.humiditySum += v
+		s.humidityCount++
+		avg := s.humiditySum / float64(s.humidityCount)
+		s.HumidityAvg = &avg
+	}
+	if v, ok := value(qc.RainLevel); ok {
+		// Diferença para a leitura anterior do pluviômetro, mesmo que de
+		// outro dia, como em ComputeDay
+		previousTime, previous, found, err := previousValue(db, station, qc.RainLevel, 0, timestamp)
+		if err != nil {
+			return err
+		}
+		if found {
+			increments, _ := meteorology.RainIncrements([]meteorology.RainSample{
+				{Timestamp: previousTime, Level: previous}, {Timestamp: timestamp, Level: v},
+			})
+			s.RainTotal += increments[1]
+		}
+	}
+	wind, windOK := value(qc.WindSpeed)
+	if gust, ok := value(qc.WindGust); ok && (!windOK || gust > wind) {
+		wind, windOK = gust, true
+	}
+	if windOK && (s.WindGustMax == nil || wind > *s.WindGustMax) {
+		s.WindGustMax, s.WindGustMaxTime = &wind, &ts
+	}
+	if v, ok := value(qc.UVIndex); ok && (s.UVMax == nil || v > *s.UVMax) {
+		s.UVMax = &v
+	}
+	if v, ok := value(qc.SolarRadiation); ok {
+		previousTime, previous, found, err := previousValue(db, station, qc.SolarRadiation, day.Unix(), timestamp)
+		if err != nil {
+			return err
+		}
+		mj := 0.0
+		if s.RadiationEnergy != nil {
+			mj = *s.RadiationEnergy
+		}
+		if found && timestamp-previousTime <= maxRadiationGap {
+			mj += (v + previous) / 2 * float64(timestamp-previousTime) / 1e6
+		}
+		s.RadiationEnergy = &mj
+	}
+	return SaveDay(db, s)
+}
+
+// loadDay lê o resumo gravado do dia com o estado da atualização incremental
+func loadDay(db *sql.DB, station, date string) (DailySummary, bool, error) {
+	s := DailySummary{Station: station, Date: date}
+	err := db.QueryRow(`
+        SELECT temp_min, temp_min_time, temp_max, temp_max_time, temp_avg, humidity_avg,
+               rain_total, wind_gust_max, wind_gust_max_time, uv_max, radiation_energy,
+               samples, temp_sum, temp_count, humidity_sum, humidity_count, last_timestamp
+        FROM daily_summary
+        WHERE station = ? AND date = ?
+    `, station, date).Scan(&s.TempMin, &s.TempMinTime, &s.TempMax, &s.TempMaxTime, &s.TempAvg,
+		&s.HumidityAvg, &s.RainTotal, &s.WindGustMax, &s.WindGustMaxTime, &s.UVMax,
+		&s.RadiationEnergy, &s.Samples, &s.tempSum, &s.tempCount, &s.humiditySum,
+		&s.humidityCount, &s.lastTimestamp)
+	if err == sql.ErrNoRows {
+		return s, false, nil
+	}
+	if err != nil {
+		return s, false, fmt.Errorf("erro ao ler resumo de %s: %w", date, err)
+	}
+	return s, true, nil
+}
+
+// previousValue retorna a última leitura aceita da métrica da estação com
+// timestamp em [from, before)
+func previousValue(db *sql.DB, station, metric string, from, before int64) (int64, float64, bool, error) {
+	var timestamp int64
+	var value float64
+	err := db.QueryRow(fmt.Sprintf(`
+        SELECT timestamp, %s FROM sensor_data
+        WHERE station = ? AND timestamp >= ? AND timestamp < ? AND %s IS NOT NULL
+          AND (qc_flags & ?) = 0
+        ORDER BY timestamp DESC LIMIT 1
+    `, metric, metric), station, from, before, qc.Mask(metric, qc.FlagRange)).Scan(&timestamp, &value)
+	if err == sql.ErrNoRows {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("erro ao buscar %s anterior: %w", metric, err)
+	}
+	return timestamp, value, true, nil
+}
+
+// Backfill reconstrói os resumos de todos os dias entre from e to
+// (inclusive) da estação, ou de cada estação com leituras no período se
+// station for vazio. Retorna a quantidade de resumos gravados
+func Backfill(db *sql.DB, station string, from, to time.Time) (int, error) {
+	ids := []string{station}
+	if station == "" {
+		var err error
+		if ids, err = stationsBetween(db, from, to); err != nil {
+			return 0, err
+		}
+	}
+
+	days := 0
+	for _, id := range ids {
+		for day := DayStart(from.Unix()); !day.After(to); day = day.AddDate(0, 0, 1) {
+			s, err := ComputeDay(db, id, day)
+			if err != nil {
+				return days, err
+			}
+			if s.Samples == 0 {
+				continue
+			}
+			if err := SaveDay(db, s); err != nil {
+				return days, fmt.Errorf("erro ao gravar %s de %s: %w", s.Date, id, err)
+			}
+			slog.Debug("Resumo diário reconstruído", "station", id, "date", s.Date, "samples", s.Samples)
+			days++
+		}
+	}
+	return days, nil
+}
+
+// stationsBetween lista as estações com leituras entre from e o fim do dia de to
+func stationsBetween(db *sql.DB, from, to time.Time) ([]string, error) {
+	end := DayStart(to.Unix()).AddDate(0, 0, 1).Unix() - 1
+	rows, err := db.Query("SELECT DISTINCT station FROM sensor_data WHERE timestamp BETWEEN ? AND ? ORDER BY station",
+		DayStart(from.Unix()).Unix(), end)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar estações: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// LoadRange lê os resumos gravados da estação entre as datas (AAAA-MM-DD, inclusive)
+func LoadRange(db *sql.DB, station, from, to string) ([]DailySummary, error) {
+	rows, err := db.Query(`
+        SELECT station, DATE_FORMAT(date, '%Y-%m-%d'), temp_min, temp_min_time, temp_max,
+               temp_max_time, temp_avg, humidity_avg, rain_total, wind_gust_max,
+               wind_gust_max_time, uv_max, radiation_energy, samples
+        FROM daily_summary
+        WHERE station = ? AND date BETWEEN ? AND ?
+        ORDER BY date
+    `, station, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []DailySummary
+	for rows.Next() {
+		var s DailySummary
+		if err := rows.Scan(&s.Station, &s.Date, &s.TempMin, &s.TempMinTime, &s.TempMax,
+			&s.TempMaxTime, &s.TempAvg, &s.HumidityAvg, &s.RainTotal, &s.WindGustMax,
+			&s.WindGustMaxTime, &s.UVMax, &s.RadiationEnergy, &s.Samples); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
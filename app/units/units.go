@@ -0,0 +1,225 @@
+package units
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Os valores são armazenados sempre no sistema métrico: temperatura em °C,
+// vento em m/s e precipitação em mm. As conversões abaixo são aplicadas
+// apenas na apresentação
+
+const (
+	// KMHPerMS converte m/s para km/h
+	KMHPerMS = 3.6
+	// MPHPerMS converte m/s para milhas por hora
+	MPHPerMS = 2.236936
+	// KnotsPerMS converte m/s para nós
+	KnotsPerMS = 1.943844
+	// MMPerInch converte polegadas para mm
+	MMPerInch = 25.4
+)
+
+// MSToKMH converte m/s para km/h
+func MSToKMH(ms float64) float64 {
+	return ms * KMHPerMS
+}
+
+// KMHToMS converte km/h para m/s
+func KMHToMS(kmh float64) float64 {
+	return kmh / KMHPerMS
+}
+
+// CelsiusToFahrenheit converte °C para °F
+func CelsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// FahrenheitToCelsius converte °F para °C
+func FahrenheitToCelsius(f float64) float64 {
+	return (f - 32) * 5 / 9
+}
+
+// Temperature é a unidade de temperatura
+type Temperature string
+
+const (
+	Celsius    Temperature = "c"
+	Fahrenheit Temperature = "f"
+)
+
+// FromCelsius converte um valor em °C para a unidade
+func (u Temperature) FromCelsius(c float64) float64 {
+	if u == Fahrenheit {
+		return CelsiusToFahrenheit(c)
+	}
+	return c
+}
+
+// Symbol retorna o símbolo da unidade
+func (u Temperature) Symbol() string {
+	if u == Fahrenheit {
+		return "°F"
+	}
+	return "°C"
+}
+
+// Speed é a unidade de velocidade do vento
+type Speed string
+
+const (
+	KMH   Speed = "kmh"
+	MS    Speed = "ms"
+	MPH   Speed = "mph"
+	Knots Speed = "kn"
+)
+
+// FromMS converte um valor em m/s para a unidade
+func (u Speed) FromMS(ms float64) float64 {
+	switch u {
+	case MS:
+		return ms
+	case MPH:
+		return ms * MPHPerMS
+	case Knots:
+		return ms * KnotsPerMS
+	default:
+		return MSToKMH(ms)
+	}
+}
+
+// FromKMH converte um valor em km/h para a unidade
+func (u Speed) FromKMH(kmh float64) float64 {
+	return u.FromMS(KMHToMS(kmh))
+}
+
+// Symbol retorna o símbolo da unidade
+func (u Speed) Symbol() string {
+	switch u {
+	case MS:
+		return "m/s"
+	case MPH:
+		return "mph"
+	case Knots:
+		return "kn"
+	default:
+		return "km/h"
+	}
+}
+
+// Precipitation é a unidade de precipitação (e de evapotranspiração)
+type Precipitation string
+
+const (
+	Millimeters Precipitation = "mm"
+	Inches      Precipitation = "in"
+)
+
+// FromMM converte um valor em mm para a unidade
+func (u Precipitation) FromMM(mm float64) float64 {
+	if u == Inches {
+		return mm / MMPerInch
+	}
+	return mm
+}
+
+// Symbol retorna o símbolo da unidade
+func (u Precipitation) Symbol() string {
+	if u == Inches {
+		return "in"
+	}
+	return "mm"
+}
+
+// System reúne as unidades escolhidas para cada grandeza
+type System struct {
+	Temperature   Temperature
+	Speed         Speed
+	Precipitation Precipitation
+}
+
+var (
+	// Metric é o sistema padrão: °C, km/h e mm
+	Metric = System{Temperature: Celsius, Speed: KMH, Precipitation: Millimeters}
+	// Imperial usa °F, mph e polegadas
+	Imperial = System{Temperature: Fahrenheit, Speed: MPH, Precipitation: Inches}
+)
+
+// Systems são os sistemas aceitos pelo parâmetro units
+var Systems = map[string]System{
+	"metric":   Metric,
+	"imperial": Imperial,
+}
+
+// Labels retorna os símbolos das unidades, incluídos nas respostas da API
+func (s System) Labels() map[string]string {
+	return map[string]string{
+		"temperature":   s.Temperature.Symbol(),
+		"wind_speed":    s.Speed.Symbol(),
+		"precipitation": s.Precipitation.Symbol(),
+	}
+}
+
+// PreferenceCookie guarda a preferência do usuário entre requisições
+const PreferenceCookie = "units"
+
+// Parse lê o sistema (units=metric|imperial) e as unidades individuais
+// (temp=c|f, wind=kmh|ms|mph|kn, rain=mm|in) a partir de fallback
+func Parse(values url.Values, fallback System) (System, error) {
+	s := fallback
+	if name := strings.ToLower(values.Get("units")); name != "" {
+		system, ok := Systems[name]
+		if !ok {
+			return s, fmt.Errorf("sistema de unidades inválido: %s", name)
+		}
+		s = system
+	}
+
+	switch temp := Temperature(strings.ToLower(values.Get("temp"))); temp {
+	case "":
+	case Celsius, Fahrenheit:
+		s.Temperature = temp
+	default:
+		return s, fmt.Errorf("unidade de temperatura inválida: %s", temp)
+	}
+
+	switch wind := Speed(strings.ToLower(values.Get("wind"))); wind {
+	case "":
+	case KMH, MS, MPH, Knots:
+		s.Speed = wind
+	case "knots":
+		s.Speed = Knots
+	default:
+		return s, fmt.Errorf("unidade de vento inválida: %s", wind)
+	}
+
+	switch rain := Precipitation(strings.ToLower(values.Get("rain"))); rain {
+	case "":
+	case Millimeters, Inches:
+		s.Precipitation = rain
+	default:
+		return s, fmt.Errorf("unidade de precipitação inválida: %s", rain)
+	}
+	return s, nil
+}
+
+// FromRequest escolhe as unidades pela query string e, na ausência dela,
+// pelo cookie de preferência, que aceita o nome do sistema ("imperial") ou o
+// mesmo formato da query ("units=imperial&wind=kn")
+func FromRequest(r *http.Request) (System, error) {
+	fallback := Metric
+	if cookie, err := r.Cookie(PreferenceCookie); err == nil {
+		value := cookie.Value
+		if !strings.Contains(value, "=") {
+			value = "units=" + value
+		}
+		if values, err := url.ParseQuery(value); err == nil {
+			if preference, err := Parse(values, Metric); err == nil {
+				fallback = preference
+			}
+		}
+	}
+	return Parse(r.URL.Query(), fallback)
+}
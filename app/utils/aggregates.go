@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"projeto/app/qc"
+	"time"
+)
+
+// Aggregate resume as leituras de um intervalo (hora ou dia local). Os
+// valores ficam inválidos quando o sensor não enviou dados aproveitáveis no
+// intervalo
+type Aggregate struct {
+	Start          int64
+	Date           string
+	Count          int
+	Temperature    sql.NullFloat64
+	TempMin        sql.NullFloat64
+	TempMax        sql.NullFloat64
+	Humidity       sql.NullFloat64
+	WindSpeed      sql.NullFloat64
+	SolarRadiation sql.NullFloat64
+}
+
+// GetHourlyAggregates retorna médias e extremos da estação por hora no intervalo
+func GetHourlyAggregates(db *sql.DB, station string, start, end int64) []Aggregate {
+	return queryAggregates(db, station, 3600, start, end)
+}
+
+// GetDailyAggregates retorna médias e extremos da estação por dia local no intervalo
+func GetDailyAggregates(db *sql.DB, station string, start, end int64) []Aggregate {
+	return queryAggregates(db, station, 86400, start, end)
+}
+
+// usable retorna a coluna da métrica como NULL quando o valor foi marcado
+// fora dos limites físicos, para que não entre em médias e extremos
+func usable(metric string) string {
+	return fmt.Sprintf("CASE WHEN (qc_flags & %d) = 0 THEN %s END", qc.Mask(metric, qc.FlagRange), metric)
+}
+
+// queryAggregates agrupa as leituras da estação em intervalos de size
+// segundos alinhados ao fuso local
+func queryAggregates(db *sql.DB, station string, size, start, end int64) []Aggregate {
+	_, offset := time.Now().In(Local).Zone()
+	temperature := usable(qc.Temperature)
+	rows, err := db.Query(`
+        SELECT
+            FLOOR((timestamp + ?) / ?) AS bucket,
+            COUNT(*),
+            AVG(`+temperature+`), MIN(`+temperature+`), MAX(`+temperature+`),
+            AVG(`+usable(qc.Humidity)+`), AVG(`+usable(qc.WindSpeed)+`),
+            AVG(`+usable(qc.SolarRadiation)+`)
+        FROM sensor_data
+        WHERE station = ? AND timestamp BETWEEN ? AND ?
+        GROUP BY bucket
+        ORDER BY bucket
+    `, offset, size, station, start, end)
+	if err != nil {
+		slog.Error("Erro na query de agregados", "err", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var aggregates []Aggregate
+	for rows.Next() {
+		var (
+			bucket                              int64
+			count                               int
+			temp, tempMin, tempMax              sql.NullFloat64
+			humidity, windSpeed, solarRadiation sql.NullFloat64
+		)
+		if err := rows.Scan(&bucket, &count, &temp, &tempMin, &tempMax,
+			&humidity, &windSpeed, &solarRadiation); err != nil {
+			slog.Error("Erro no scan", "err", err)
+			continue
+		}
+
+		bucketStart := bucket*size - int64(offset)
+		aggregates = append(aggregates, Aggregate{
+			Start:          bucketStart,
+			Date:           time.Unix(bucketStart, 0).In(Local).Format("2006-01-02"),
+			Count:          count,
+			Temperature:    temp,
+			TempMin:        tempMin,
+			TempMax:        tempMax,
+			Humidity:       humidity,
+			WindSpeed:      windSpeed,
+			SolarRadiation: solarRadiation,
+		})
+	}
+	return aggregates
+}
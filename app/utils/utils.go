@@ -2,103 +2,207 @@ package utils
 
 import (
 	"database/sql"
-	"log"
+	"fmt"
+	"log/slog"
 	"math"
+	"net/url"
+	"projeto/app/classification"
+	"projeto/app/config"
+	"projeto/app/i18n"
+	"projeto/app/meteorology"
+	"projeto/app/qc"
+	"projeto/app/units"
 	"strconv"
+	"strings"
+	"time"
 )
 
-// Prepara os dados para o template
-func PrepareTemplateData(currentData, previousData map[string]interface{}) map[string]interface{} {
+// Prepara os dados para o template, com os valores convertidos para as
+// unidades escolhidas
+func PrepareTemplateData(currentData, previousData map[string]interface{}, system units.System, locale i18n.Locale) map[string]interface{} {
+	common := map[string]interface{}{
+		"Lang":              locale,
+		"TemperatureUnit":   system.Temperature.Symbol(),
+		"SpeedUnit":         system.Speed.Symbol(),
+		"PrecipitationUnit": system.Precipitation.Symbol(),
+	}
+
 	if currentData == nil {
-		return map[string]interface{}{
-			"Message":           "Nenhum dado disponível no momento.",
-			"WindDirection":     "N/D",
+		data := map[string]interface{}{
+			"Message":           locale.T("message.no_data"),
+			"WindDirection":     DirectionLabel(locale, ""),
 			"WindIconClass":     "rotate-0",
-			"UVStatus":          "N/A",
-			"HumidityStatus":    "N/A",
-			"RainStatus":        "N/A",
-			"TemperatureStatus": "N/A",
+			"UVStatus":          StatusLabel(locale, "", "uv", ""),
+			"HumidityStatus":    StatusLabel(locale, "", "humidity", ""),
+			"RainStatus":        StatusLabel(locale, "", "rain", ""),
+			"TemperatureStatus": StatusLabel(locale, "", "temperature", ""),
 			"Temperature":       0.0,
 			"UVIndex":           0.0,
 			"Humidity":          0.0,
 			"RainLevel":         0.0,
 			"AverageWindSpeed":  0.0,
-			"WindSpeedStatus":   "N/A",
-			"WindSpeedKMH":      0.0,
+			"WindSpeedStatus":   StatusLabel(locale, "", "wind", ""),
+			"WindSpeed":         0.0,
+			"DewPoint":          0.0,
+			"HeatIndex":         0.0,
+			"WindChill":         0.0,
+			"ApparentTemp":      0.0,
+			"FeelsLike":         0.0,
+		}
+		for k, v := range common {
+			data[k] = v
 		}
+		return data
 	}
 
 	// Extrair os valores de currentData
-	windDirectionRad := getFloatFromMap(currentData, "wind_direction")
-	uvIndex := getFloatFromMap(currentData, "uv_index")
-	humidity := getFloatFromMap(currentData, "humidity")
-	currentRainLevel := getFloatFromMap(currentData, "rain_level")
-	temperature := getFloatFromMap(currentData, "temperature")
-	averageWindSpeed := getFloatFromMap(currentData, "average_wind_speed")
+	windDirectionRad := GetFloatFromMap(currentData, "wind_direction")
+	uvIndex := GetFloatFromMap(currentData, "uv_index")
+	humidity := GetFloatFromMap(currentData, "humidity")
+	currentRainLevel := GetFloatFromMap(currentData, "rain_level")
+	temperature := GetFloatFromMap(currentData, "temperature")
+	averageWindSpeed := GetFloatFromMap(currentData, "average_wind_speed")
+	station, _ := currentData["station"].(string)
 
 	// Calcular o nível de chuva anterior
 	previousRainLevel := currentRainLevel
 	if previousData != nil {
-		previousRainLevel = getFloatFromMap(previousData, "rain_level")
+		previousRainLevel = GetFloatFromMap(previousData, "rain_level")
 	}
 
 	// Converter radianos para direção e ícone
 	windDirection, windIconClass := RadToDirectionWithIcon(windDirectionRad)
+	windSpeedKMH := units.MSToKMH(averageWindSpeed)
 
-	// Preparar o contexto para o template
-	return map[string]interface{}{
-		"WindDirection":     windDirection,
+	// As classificações usam sempre os valores métricos
+	data := map[string]interface{}{
+		"WindDirection":     DirectionLabel(locale, windDirection),
 		"WindIconClass":     windIconClass,
-		"UVStatus":          GetUVStatus(uvIndex),
-		"HumidityStatus":    GetHumidityStatus(humidity),
-		"RainStatus":        GetRainStatus(currentRainLevel, previousRainLevel),
-		"TemperatureStatus": GetTemperatureStatus(temperature),
-		"Temperature":       temperature,
+		"UVStatus":          StatusLabel(locale, station, "uv", GetUVStatus(station, uvIndex)),
+		"HumidityStatus":    StatusLabel(locale, station, "humidity", GetHumidityStatus(station, humidity)),
+		"RainStatus":        StatusLabel(locale, station, "rain", GetRainStatus(station, currentRainLevel, previousRainLevel)),
+		"TemperatureStatus": StatusLabel(locale, station, "temperature", GetTemperatureStatus(station, temperature)),
+		"Temperature":       system.Temperature.FromCelsius(temperature),
 		"UVIndex":           uvIndex,
 		"Humidity":          humidity,
-		"RainLevel":         currentRainLevel,
-		"AverageWindSpeed":  averageWindSpeed * 3.6, // Converter m/s para km/h
-		"WindSpeedStatus":   GetWindSpeedStatus(averageWindSpeed * 3.6),
+		"RainLevel":         system.Precipitation.FromMM(currentRainLevel),
+		"AverageWindSpeed":  system.Speed.FromMS(averageWindSpeed),
+		"WindSpeed":         system.Speed.FromMS(averageWindSpeed),
+		"WindSpeedStatus":   StatusLabel(locale, station, "wind", GetWindSpeedStatus(station, windSpeedKMH)),
+		"DewPoint":          system.Temperature.FromCelsius(meteorology.DewPoint(temperature, humidity)),
+		"HeatIndex":         system.Temperature.FromCelsius(meteorology.HeatIndex(temperature, humidity)),
+		"WindChill":         system.Temperature.FromCelsius(meteorology.WindChill(temperature, windSpeedKMH)),
+		"ApparentTemp":      system.Temperature.FromCelsius(meteorology.ApparentTemperature(temperature, humidity, averageWindSpeed)),
+		"FeelsLike":         system.Temperature.FromCelsius(meteorology.FeelsLike(temperature, humidity, windSpeedKMH)),
+	}
+	for k, v := range common {
+		data[k] = v
 	}
+	return data
 }
 
-func PrepareAPIData(currentData, previousData map[string]interface{}) map[string]interface{} {
+// PrepareAPIData monta a leitura atual para a API, com os valores convertidos
+// para as unidades escolhidas. wind_speed_kmh é mantido em km/h por
+// compatibilidade; wind_speed segue a unidade pedida. Cada status é
+// devolvido como texto traduzido (*_status) e como código estável (*_status_code)
+func PrepareAPIData(currentData, previousData map[string]interface{}, system units.System, locale i18n.Locale) map[string]interface{} {
 	if currentData == nil {
 		return map[string]interface{}{
-			"temperature":        0.0,
-			"temperature_status": "N/A",
-			"humidity":           0.0,
-			"humidity_status":    "N/A",
-			"rain_level":         0.0,
-			"rain_status":        "N/A",
-			"uv_index":           0.0,
-			"uv_status":          "N/A",
-			"wind_speed_kmh":     0.0,
-			"wind_speed_status":  "N/A",
-			"wind_direction":     "N/D",
+			"temperature":             0.0,
+			"temperature_status":      StatusLabel(locale, "", "temperature", ""),
+			"temperature_status_code": "",
+			"humidity":                0.0,
+			"humidity_status":         StatusLabel(locale, "", "humidity", ""),
+			"humidity_status_code":    "",
+			"rain_level":              0.0,
+			"rain_status":             StatusLabel(locale, "", "rain", ""),
+			"rain_status_code":        "",
+			"uv_index":                0.0,
+			"uv_status":               StatusLabel(locale, "", "uv", ""),
+			"uv_status_code":          "",
+			"wind_speed":              0.0,
+			"wind_speed_kmh":          0.0,
+			"wind_speed_status":       StatusLabel(locale, "", "wind", ""),
+			"wind_speed_status_code":  "",
+			"wind_direction":          DirectionLabel(locale, ""),
+			"wind_direction_code":     "",
+			"wind_gust":               nil,
+			"wind_gust_kmh":           nil,
+			"gust_factor":             nil,
+			"beaufort":                nil,
+			"dew_point":               0.0,
+			"heat_index":              0.0,
+			"wind_chill":              0.0,
+			"apparent_temp":           0.0,
+			"feels_like":              0.0,
+			"units":                   system.Labels(),
+			"lang":                    locale,
 		}
 	}
 
-	windDirectionRad := getFloatFromMap(currentData, "wind_direction")
+	windDirectionRad := GetFloatFromMap(currentData, "wind_direction")
 	windDirection, _ := RadToDirectionWithIcon(windDirectionRad)
+	temperature := GetFloatFromMap(currentData, "temperature")
+	humidity := GetFloatFromMap(currentData, "humidity")
+	windSpeed := GetFloatFromMap(currentData, "average_wind_speed")
+	windSpeedKMH := units.MSToKMH(windSpeed)
+	rainLevel := GetFloatFromMap(currentData, "rain_level")
+	uvIndex := GetFloatFromMap(currentData, "uv_index")
+	station, _ := currentData["station"].(string)
+
+	// Classificação pelas faixas configuradas para a estação
+	temperatureStatus := GetTemperatureStatus(station, temperature)
+	humidityStatus := GetHumidityStatus(station, humidity)
+	rainStatus := GetRainStatus(station, rainLevel, GetFloatFromMap(previousData, "rain_level"))
+	uvStatus := GetUVStatus(station, uvIndex)
+	windStatus := GetWindSpeedStatus(station, windSpeedKMH)
+
+	// Rajada e fator de rajada só quando a estação envia a rajada
+	var windGust, windGustKMH, gustFactor interface{}
+	if gust, ok := currentData["wind_gust"].(float64); ok {
+		windGust = system.Speed.FromMS(gust)
+		windGustKMH = units.MSToKMH(gust)
+		if factor, ok := meteorology.GustFactor(gust, windSpeed); ok {
+			gustFactor = factor
+		}
+	}
 
 	return map[string]interface{}{
-		"temperature":        getFloatFromMap(currentData, "temperature"),
-		"temperature_status": GetTemperatureStatus(getFloatFromMap(currentData, "temperature")),
-		"humidity":           getFloatFromMap(currentData, "humidity"),
-		"humidity_status":    GetHumidityStatus(getFloatFromMap(currentData, "humidity")),
-		"rain_level":         getFloatFromMap(currentData, "rain_level"),
-		"rain_status":        GetRainStatus(getFloatFromMap(currentData, "rain_level"), getFloatFromMap(previousData, "rain_level")),
-		"uv_index":           getFloatFromMap(currentData, "uv_index"),
-		"uv_status":          GetUVStatus(getFloatFromMap(currentData, "uv_index")),
-		"wind_speed_kmh":     getFloatFromMap(currentData, "average_wind_speed") * 3.6,
-		"wind_speed_status":  GetWindSpeedStatus(getFloatFromMap(currentData, "average_wind_speed") * 3.6),
-		"wind_direction":     windDirection,
+		"temperature":             system.Temperature.FromCelsius(temperature),
+		"temperature_status":      StatusLabel(locale, station, "temperature", temperatureStatus),
+		"temperature_status_code": temperatureStatus,
+		"humidity":                humidity,
+		"humidity_status":         StatusLabel(locale, station, "humidity", humidityStatus),
+		"humidity_status_code":    humidityStatus,
+		"rain_level":              system.Precipitation.FromMM(rainLevel),
+		"rain_status":             StatusLabel(locale, station, "rain", rainStatus),
+		"rain_status_code":        rainStatus,
+		"uv_index":                uvIndex,
+		"uv_status":               StatusLabel(locale, station, "uv", uvStatus),
+		"uv_status_code":          uvStatus,
+		"wind_speed":              system.Speed.FromMS(windSpeed),
+		"wind_speed_kmh":          windSpeedKMH,
+		"wind_speed_status":       StatusLabel(locale, station, "wind", windStatus),
+		"wind_speed_status_code":  windStatus,
+		"wind_direction":          DirectionLabel(locale, windDirection),
+		"wind_direction_code":     windDirection,
+		"wind_gust":               windGust,
+		"wind_gust_kmh":           windGustKMH,
+		"gust_factor":             gustFactor,
+		"beaufort":                BeaufortInfo(locale, meteorology.BeaufortAt(windSpeed, config.Site().WindHeight)),
+		"dew_point":               system.Temperature.FromCelsius(meteorology.DewPoint(temperature, humidity)),
+		"heat_index":              system.Temperature.FromCelsius(meteorology.HeatIndex(temperature, humidity)),
+		"wind_chill":              system.Temperature.FromCelsius(meteorology.WindChill(temperature, windSpeedKMH)),
+		"apparent_temp":           system.Temperature.FromCelsius(meteorology.ApparentTemperature(temperature, humidity, windSpeed)),
+		"feels_like":              system.Temperature.FromCelsius(meteorology.FeelsLike(temperature, humidity, windSpeedKMH)),
+		"station":                 station,
+		"units":                   system.Labels(),
+		"lang":                    locale,
 	}
 }
 
-// Função auxiliar para extrair valores float de um mapa
-func getFloatFromMap(data map[string]interface{}, key string) float64 {
+// GetFloatFromMap extrai valores float de um mapa
+func GetFloatFromMap(data map[string]interface{}, key string) float64 {
 	if value, exists := data[key]; exists {
 		switch v := value.(type) {
 		case float64:
@@ -114,21 +218,31 @@ func getFloatFromMap(data map[string]interface{}, key string) float64 {
 
 // GetMySQLData retorna os dois últimos registros do banco
 func GetMySQLData(db *sql.DB) (map[string]interface{}, map[string]interface{}) {
+	return GetStationData(db, "")
+}
+
+// GetStationData retorna os dois últimos registros da estação (vazio para
+// qualquer estação)
+func GetStationData(db *sql.DB, station string) (map[string]interface{}, map[string]interface{}) {
 	rows, err := db.Query(`
         SELECT 
             rain_level, 
             average_wind_speed,  -- Nome correto da coluna
             wind_direction,
+            wind_gust,
             humidity,
             uv_index,
             temperature,
-            timestamp
+            timestamp,
+            qc_flags,
+            station
         FROM sensor_data
+        WHERE ? = '' OR station = ?
         ORDER BY timestamp DESC
         LIMIT 2
-    `)
+    `, station, station)
 	if err != nil {
-		log.Printf("Erro na query: %v", err) // 👈 Log de erro
+		slog.Error("Erro na query", "err", err)
 		return nil, nil
 	}
 	defer rows.Close()
@@ -141,10 +255,13 @@ func GetMySQLData(db *sql.DB) (map[string]interface{}, map[string]interface{}) {
 			rainLevel        sql.NullFloat64
 			averageWindSpeed sql.NullFloat64
 			windDirection    sql.NullFloat64
+			windGust         sql.NullFloat64
 			humidity         sql.NullFloat64
 			uvIndex          sql.NullFloat64
 			temperature      sql.NullFloat64
 			timestamp        sql.NullInt64
+			qcFlags          int64
+			station          string
 		)
 
 		// Scan com tipos seguros
@@ -152,12 +269,15 @@ func GetMySQLData(db *sql.DB) (map[string]interface{}, map[string]interface{}) {
 			&rainLevel,
 			&averageWindSpeed,
 			&windDirection,
+			&windGust,
 			&humidity,
 			&uvIndex,
 			&temperature,
 			&timestamp,
+			&qcFlags,
+			&station,
 		); err != nil {
-			log.Printf("Erro no scan: %v", err) // 👈 Log de erro
+			slog.Error("Erro no scan", "err", err)
 			return nil, nil
 		}
 
@@ -170,9 +290,14 @@ func GetMySQLData(db *sql.DB) (map[string]interface{}, map[string]interface{}) {
 			"uv_index":           uvIndex.Float64,
 			"temperature":        temperature.Float64,
 			"timestamp":          timestamp.Int64,
+			"qc_flags":           qcFlags,
+			"station":            station,
+		}
+		if windGust.Valid {
+			data["wind_gust"] = windGust.Float64
 		}
 
-		log.Printf("Dado processado: %+v", data) // 👈 Log detalhado
+		slog.Debug("Dado processado", "timestamp", timestamp.Int64)
 		results = append(results, data)
 	}
 
@@ -182,24 +307,153 @@ func GetMySQLData(db *sql.DB) (map[string]interface{}, map[string]interface{}) {
 		return results[0], nil
 	}
 
-	log.Println("Nenhum dado encontrado") // 👈 Log informativo
+	slog.Info("Nenhum dado encontrado")
 	return nil, nil
 }
 
-// RadToDirectionWithIcon converte radianos para direção cardeal
+// Local é o fuso horário da estação (UTC-3)
+var Local = time.FixedZone("UTC-3", -3*3600)
+
+// ParseRange lê o período de uma consulta: "start" e "end" como datas
+// (AAAA-MM-DD, fim inclusivo) no fuso local, ou "period" como duração
+// ("24h", "7d"). Sem parâmetros, usa defaultPeriod até agora
+func ParseRange(query url.Values, now time.Time, defaultPeriod time.Duration) (int64, int64, error) {
+	end := now.Unix()
+
+	if startParam := query.Get("start"); startParam != "" {
+		start, err := time.ParseInLocation("2006-01-02", startParam, Local)
+		if err != nil {
+			return 0, 0, fmt.Errorf("data inicial inválida: %s", startParam)
+		}
+		if endParam := query.Get("end"); endParam != "" {
+			endDay, err := time.ParseInLocation("2006-01-02", endParam, Local)
+			if err != nil {
+				return 0, 0, fmt.Errorf("data final inválida: %s", endParam)
+			}
+			end = endDay.AddDate(0, 0, 1).Unix() - 1
+		}
+		if start.Unix() > end {
+			return 0, 0, fmt.Errorf("período inválido: início após o fim")
+		}
+		return start.Unix(), end, nil
+	}
+
+	period := defaultPeriod
+	if periodParam := query.Get("period"); periodParam != "" {
+		var err error
+		period, err = parsePeriod(periodParam)
+		if err != nil || period <= 0 {
+			return 0, 0, fmt.Errorf("período inválido: %s", periodParam)
+		}
+	}
+	return now.Add(-period).Unix(), end, nil
+}
+
+// parsePeriod aceita durações do Go e a unidade "d" para dias
+func parsePeriod(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// TodayRange retorna o início e o fim do dia local (UTC-3) em UNIX
+func TodayRange(now time.Time) (int64, int64) {
+	localNow := now.UTC().Add(-3 * time.Hour)
+	startDay := time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, time.UTC)
+	startTimestamp := startDay.Unix() + 3*3600
+	return startTimestamp, startTimestamp + 24*3600
+}
+
+// GetRainSamples retorna as leituras do pluviômetro da estação a partir de
+// since, em ordem cronológica. Cada estação tem o seu contador acumulado,
+// então as leituras nunca misturam estações. Valores fora dos limites
+// físicos são ignorados
+func GetRainSamples(db *sql.DB, station string, since int64) []meteorology.RainSample {
+	rows, err := db.Query(`
+        SELECT timestamp, rain_level
+        FROM sensor_data
+        WHERE station = ? AND timestamp >= ? AND rain_level IS NOT NULL
+          AND (qc_flags & ?) = 0
+        ORDER BY timestamp
+    `, station, since, qc.Mask(qc.RainLevel, qc.FlagRange))
+	if err != nil {
+		slog.Error("Erro na query de chuva", "err", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var samples []meteorology.RainSample
+	for rows.Next() {
+		var sample meteorology.RainSample
+		if err := rows.Scan(&sample.Timestamp, &sample.Level); err != nil {
+			slog.Error("Erro no scan", "err", err)
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
+// GetWindSamples retorna as leituras de vento da estação no intervalo, em
+// ordem cronológica, sem os valores fora dos limites físicos
+func GetWindSamples(db *sql.DB, station string, start, end int64) []meteorology.WindSample {
+	rows, err := db.Query(`
+        SELECT timestamp, average_wind_speed, wind_direction, `+usable(qc.WindGust)+`
+        FROM sensor_data
+        WHERE station = ? AND timestamp BETWEEN ? AND ?
+          AND average_wind_speed IS NOT NULL AND wind_direction IS NOT NULL
+          AND (qc_flags & ?) = 0
+        ORDER BY timestamp
+    `, station, start, end, qc.Mask(qc.WindSpeed, qc.FlagRange)|qc.Mask(qc.WindDirection, qc.FlagRange))
+	if err != nil {
+		slog.Error("Erro na query de vento", "err", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var samples []meteorology.WindSample
+	for rows.Next() {
+		var sample meteorology.WindSample
+		var gust sql.NullFloat64
+		if err := rows.Scan(&sample.Timestamp, &sample.Speed, &sample.Direction, &gust); err != nil {
+			slog.Error("Erro no scan", "err", err)
+			continue
+		}
+		sample.Gust = gust.Float64
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
+// GetRainAccumulation calcula taxa e acumulados de chuva da estação
+// considerando as últimas 48 horas, o que limita a duração máxima de um
+// evento de chuva
+func GetRainAccumulation(db *sql.DB, station string, now time.Time) meteorology.RainAccumulation {
+	dayStart, _ := TodayRange(now)
+	samples := GetRainSamples(db, station, now.Add(-48*time.Hour).Unix())
+	return meteorology.AccumulateRain(samples, now, dayStart)
+}
+
+// RadToDirectionWithIcon converte radianos para o código da direção cardeal
+// (N, NE, ..., NW) e a classe do ícone. O nome por extenso vem de DirectionLabel
 func RadToDirectionWithIcon(rad float64) (string, string) {
 	directions := []struct {
 		Name string
 		Icon string
 	}{
-		{"Norte", "rotate-0"},
-		{"Nordeste", "rotate-45"},
-		{"Leste", "rotate-90"},
-		{"Sudeste", "rotate-135"},
-		{"Sul", "rotate-180"},
-		{"Sudoeste", "rotate-225"},
-		{"Oeste", "rotate-270"},
-		{"Noroeste", "rotate-315"},
+		{"N", "rotate-0"},
+		{"NE", "rotate-45"},
+		{"E", "rotate-90"},
+		{"SE", "rotate-135"},
+		{"S", "rotate-180"},
+		{"SW", "rotate-225"},
+		{"W", "rotate-270"},
+		{"NW", "rotate-315"},
 	}
 	rad = math.Mod(rad, 2*math.Pi)
 	index := (int((rad+math.Pi/8)/(math.Pi/4))%8 + 8) % 8
@@ -244,79 +498,68 @@ func CalculateMin(values []float64) float64 {
 	return min
 }
 
-// GetUVStatus determina o status da radiação UV
-func GetUVStatus(uvIndex float64) string {
-	switch {
-	case uvIndex < 3:
-		return "Níveis baixos de UV"
-	case uvIndex < 6:
-		return "Níveis moderados de UV"
-	case uvIndex < 8:
-		return "Níveis altos de UV"
-	case uvIndex < 11:
-		return "Níveis muito altos de UV"
-	default:
-		return "Risco extremo de UV"
+// Os Get*Status retornam códigos estáveis (ex.: "pleasant"), usados pela API
+// em *_status_code, a partir das faixas configuradas para a estação. O texto
+// exibido vem do catálogo, por StatusLabel
+
+// StatusLabel traduz o código de status de uma grandeza
+// ("temperature", "humidity", "rain", "uv" ou "wind"). Códigos criados na
+// configuração de faixas usam os rótulos definidos na tabela da estação
+func StatusLabel(locale i18n.Locale, station, kind, code string) string {
+	if code == "" {
+		return locale.T("status.unknown")
+	}
+	key := "status." + kind + "." + code
+	if locale.Has(key) {
+		return locale.T(key)
+	}
+	if label, ok := classification.Current().Label(station, kind, code, string(locale)); ok {
+		return label
 	}
+	if label, ok := classification.Current().Label(station, kind, code, string(i18n.Default)); ok {
+		return label
+	}
+	return code
 }
 
-func GetHumidityStatus(humidity float64) string {
-	switch {
-	case humidity < 30:
-		return "Ar muito seco"
-	case humidity < 60:
-		return "Umidade confortável"
-	default:
-		return "Ar muito úmido"
+// DirectionLabel traduz o código de direção devolvido por RadToDirectionWithIcon
+func DirectionLabel(locale i18n.Locale, code string) string {
+	if code == "" {
+		return locale.T("direction.unknown")
 	}
+	return locale.T("direction." + code)
 }
 
-func GetRainStatus(currentRainLevel, previousRainLevel float64) string {
-	rainDifference := currentRainLevel - previousRainLevel
-	switch {
-	case rainDifference <= 0:
-		return "Não está chovendo"
-	case rainDifference < 0.0006:
-		return "Chuviscando"
-	default:
-		return "Chovendo"
+// BeaufortInfo descreve o grau da escala de Beaufort no idioma pedido, com o
+// aspecto provável do mar
+func BeaufortInfo(locale i18n.Locale, b meteorology.Beaufort) map[string]interface{} {
+	return map[string]interface{}{
+		"number":        b.Number,
+		"code":          b.Code,
+		"description":   locale.T("beaufort." + b.Code),
+		"sea_state":     locale.T("beaufort.sea." + b.Code),
+		"wave_height_m": b.WaveHeight,
 	}
 }
 
-func GetTemperatureStatus(temperature float64) string {
-	switch {
-	case temperature < 10:
-		return "Frio intenso"
-	case temperature < 20:
-		return "Clima frio"
-	case temperature < 25:
-		return "Clima agradável"
-	case temperature < 30:
-		return "Clima quente"
-	default:
-		return "Calor extremo"
-	}
+// GetUVStatus determina o status da radiação UV
+func GetUVStatus(station string, uvIndex float64) string {
+	return classification.Current().Classify(station, classification.UV, uvIndex)
 }
 
-func GetWindSpeedStatus(windSpeedKMH float64) string {
-	switch {
-	case windSpeedKMH == 0:
-		return "Sem vento"
-	case windSpeedKMH < 12.0:
-		return "Brisa leve"
-	case windSpeedKMH < 20.0:
-		return "Vento fresco"
-	case windSpeedKMH < 41.0:
-		return "Vento moderado"
-	case windSpeedKMH < 62.0:
-		return "Vento forte"
-	case windSpeedKMH < 75.0:
-		return "Vento muito forte"
-	case windSpeedKMH < 103.0:
-		return "Vendaval severo"
-	case windSpeedKMH < 120.0:
-		return "Tempestade"
-	default:
-		return "Ciclone tropical"
-	}
+func GetHumidityStatus(station string, humidity float64) string {
+	return classification.Current().Classify(station, classification.Humidity, humidity)
+}
+
+func GetRainStatus(station string, currentRainLevel, previousRainLevel float64) string {
+	rainDifference := currentRainLevel - previousRainLevel
+	return classification.Current().Classify(station, classification.Rain, rainDifference)
+}
+
+func GetTemperatureStatus(station string, temperature float64) string {
+	return classification.Current().Classify(station, classification.Temperature, temperature)
+}
+
+func GetWindSpeedStatus(station string, windSpeedKMH float64) string {
+	return classification.Current().Classify(station, classification.Wind, windSpeedKMH)
 }
@@ -1,17 +1,61 @@
 package main
 
 import (
+	"fmt"
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"projeto/app/alerts"
+	"projeto/app/broker"
+	"projeto/app/classification"
+	"projeto/app/commands"
+	"projeto/app/devices"
 	"projeto/app/handlers"
+	"projeto/app/i18n"
+	"projeto/app/ingest"
+	"projeto/app/logger"
 	"projeto/app/mqtt"
+	"projeto/app/notify"
+	"projeto/app/stations"
 )
 
-var templates = template.Must(template.ParseGlob("templates/*.html"))
+var templates = template.Must(template.New("").Funcs(i18n.FuncMap).ParseGlob("templates/*.html"))
 
 func main() {
-	go mqtt.SetupMQTT()
+	logger.Setup()
+
+	// Subcomandos de manutenção: go run . <comando> [opções]
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			slog.Error("Falha no comando", "command", os.Args[1], "err", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	classification.Setup()
+	alerts.Setup(ingest.AlertMetrics())
+	notify.Setup(alerts.Default(), mqtt.Publish)
+	stations.Setup(alerts.Default())
+	devices.Setup()
+
+	// Com MQTT_EMBEDDED_LISTEN o broker roda no próprio processo e o cliente
+	// principal se conecta a ele em vez do broker externo
+	brokerURL := mqtt.BrokerURL()
+	if cfg, enabled, err := broker.ConfigFromEnv(); err != nil {
+		slog.Error("Configuração do broker MQTT embutido inválida", "err", err)
+		os.Exit(1)
+	} else if enabled {
+		embedded, err := broker.Start(cfg)
+		if err != nil {
+			slog.Error("Falha ao iniciar o broker MQTT embutido", "err", err)
+			os.Exit(1)
+		}
+		defer embedded.Close()
+		brokerURL = embedded.LocalURL()
+	}
+	go mqtt.SetupMQTT(brokerURL)
 
 	// Carregar as imagens
 	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
@@ -20,12 +64,49 @@ func main() {
 	http.HandleFunc("/", handlers.Index(templates))
 	http.HandleFunc("/dados", handlers.Dashboard(templates))
 	http.HandleFunc("/temperatura", handlers.PlotData(templates))
+	http.HandleFunc("/irrigacao", handlers.Irrigation(templates))
+	http.HandleFunc("/vento", handlers.WindPage(templates))
 
 	// Novas rotas da API
 	http.HandleFunc("/api", handlers.ApiIndexHandler)
 	http.HandleFunc("/api/dados", handlers.ApiDashboardHandler)
 	http.HandleFunc("/api/temperatura", handlers.ApiTemperatureHandler)
+	http.HandleFunc("/api/windrose", handlers.ApiWindRoseHandler)
+	http.HandleFunc("/api/et0", handlers.ApiET0Handler)
+	http.HandleFunc("/api/grausdia", handlers.ApiDegreeDaysHandler)
+	http.HandleFunc("/api/relatorio", handlers.ApiReportHandler)
+	http.HandleFunc("/api/almanaque", handlers.ApiAlmanacHandler)
+	http.HandleFunc("/api/alertas", handlers.ApiAlertsHandler)
+	http.HandleFunc("/api/estacoes", handlers.ApiStationsHandler)
+	http.HandleFunc("/api/calibracoes", handlers.ApiCalibrationsHandler)
+	http.HandleFunc("/health", handlers.HealthHandler)
+	if err := handlers.RegisterV1(http.DefaultServeMux); err != nil {
+		slog.Error("Falha ao registrar a API v1", "err", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Servidor rodando na porta 8080")
+	if err := http.ListenAndServe(":8080", logger.Middleware(http.DefaultServeMux)); err != nil {
+		slog.Error("Servidor encerrado", "err", err)
+	}
+}
 
-	log.Println("Servidor rodando na porta 8080")
-	http.ListenAndServe(":8080", nil)
+// runCommand executa um subcomando de linha de comando
+func runCommand(name string, args []string) error {
+	switch name {
+	case "backfill":
+		return commands.Backfill(args)
+	case "calibrate":
+		return commands.Calibrate(args)
+	case "import":
+		return commands.Import(args)
+	case "record":
+		return commands.Record(args)
+	case "replay":
+		return commands.Replay(args)
+	case "simulate":
+		return commands.Simulate(args)
+	default:
+		return fmt.Errorf("comando desconhecido: %s", name)
+	}
 }
@@ -4,13 +4,118 @@ USE weather_data;
 -- Tabela para armazenar os dados dos sensores
 CREATE TABLE IF NOT EXISTS sensor_data (
     id INT AUTO_INCREMENT PRIMARY KEY,
+    station VARCHAR(64) NOT NULL DEFAULT 'konda',
     rain_level FLOAT NULL,
     average_wind_speed FLOAT NULL,
     wind_direction FLOAT NULL,
     humidity FLOAT NULL,
     uv_index FLOAT NULL,
     solar_radiation FLOAT NULL,
+    wind_gust FLOAT NULL, -- rajada (m/s), quando a estação envia
     temperature FLOAT NULL,
     timestamp BIGINT NOT NULL, -- Armazena o tempo em formato UNIX UTC (padrão)
-    UNIQUE KEY unique_timestamp (timestamp)
+    qc_flags BIGINT NOT NULL DEFAULT 0, -- Indicadores de qualidade, 4 bits por métrica (ver app/qc)
+    UNIQUE KEY unique_timestamp (station, timestamp)
+);
+-- Bancos existentes:
+-- ALTER TABLE sensor_data ADD COLUMN qc_flags BIGINT NOT NULL DEFAULT 0;
+-- ALTER TABLE sensor_data ADD COLUMN station VARCHAR(64) NOT NULL DEFAULT 'konda' AFTER id,
+--     DROP INDEX unique_timestamp, ADD UNIQUE KEY unique_timestamp (station, timestamp);
+-- ALTER TABLE sensor_data ADD COLUMN wind_gust FLOAT NULL AFTER solar_radiation;
+-- ALTER TABLE sensor_data_raw ADD COLUMN wind_gust FLOAT NULL;
+
+-- Valores brutos recebidos, antes da calibração, para permitir o recálculo
+CREATE TABLE IF NOT EXISTS sensor_data_raw (
+    station VARCHAR(64) NOT NULL,
+    timestamp BIGINT NOT NULL,
+    temperature FLOAT NULL,
+    humidity FLOAT NULL,
+    rain_level FLOAT NULL,
+    average_wind_speed FLOAT NULL,
+    wind_direction FLOAT NULL,
+    uv_index FLOAT NULL,
+    solar_radiation FLOAT NULL,
+    wind_gust FLOAT NULL,
+    PRIMARY KEY (station, timestamp)
+);
+
+-- Calibrações por estação e métrica: valor = gain * p(bruto) + offset_value,
+-- com p o polinômio de coefficients (JSON [c0, c1, ...]) quando informado
+CREATE TABLE IF NOT EXISTS calibrations (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    station VARCHAR(64) NOT NULL,
+    metric VARCHAR(64) NOT NULL,
+    offset_value DOUBLE NOT NULL DEFAULT 0,
+    gain DOUBLE NOT NULL DEFAULT 1,
+    coefficients VARCHAR(255) NULL,
+    effective_from BIGINT NOT NULL,
+    effective_to BIGINT NULL,
+    note VARCHAR(255) NULL,
+    created_at BIGINT NOT NULL,
+    KEY idx_calibrations_station_metric (station, metric)
+);
+
+-- Resumo diário por estação e dia local (UTC-3), atualizado a cada leitura
+-- recebida e reconstruível com o comando "backfill"
+CREATE TABLE IF NOT EXISTS daily_summary (
+    station VARCHAR(64) NOT NULL DEFAULT 'konda',
+    date DATE NOT NULL,
+    temp_min FLOAT NULL,
+    temp_min_time BIGINT NULL,
+    temp_max FLOAT NULL,
+    temp_max_time BIGINT NULL,
+    temp_avg FLOAT NULL,
+    humidity_avg FLOAT NULL,
+    rain_total FLOAT NOT NULL DEFAULT 0, -- mm
+    wind_gust_max FLOAT NULL, -- m/s
+    wind_gust_max_time BIGINT NULL,
+    uv_max FLOAT NULL,
+    radiation_energy FLOAT NULL, -- MJ/m²
+    samples INT NOT NULL DEFAULT 0,
+    -- Somas e contagens das médias e a última leitura incorporada, usadas na
+    -- atualização incremental a cada leitura ao vivo
+    temp_sum DOUBLE NOT NULL DEFAULT 0,
+    temp_count INT NOT NULL DEFAULT 0,
+    humidity_sum DOUBLE NOT NULL DEFAULT 0,
+    humidity_count INT NOT NULL DEFAULT 0,
+    last_timestamp BIGINT NOT NULL DEFAULT 0,
+    updated_at BIGINT NOT NULL,
+    PRIMARY KEY (station, date)
+);
+-- Bancos existentes:
+-- ALTER TABLE daily_summary ADD COLUMN station VARCHAR(64) NOT NULL DEFAULT 'konda' FIRST,
+--     DROP PRIMARY KEY, ADD PRIMARY KEY (station, date);
+-- Os resumos antigos misturavam as estações: reconstrua-os com "backfill"
+-- ALTER TABLE daily_summary ADD COLUMN temp_sum DOUBLE NOT NULL DEFAULT 0 AFTER samples,
+--     ADD COLUMN temp_count INT NOT NULL DEFAULT 0 AFTER temp_sum,
+--     ADD COLUMN humidity_sum DOUBLE NOT NULL DEFAULT 0 AFTER temp_count,
+--     ADD COLUMN humidity_count INT NOT NULL DEFAULT 0 AFTER humidity_sum,
+--     ADD COLUMN last_timestamp BIGINT NOT NULL DEFAULT 0 AFTER humidity_count;
+-- Resumos com last_timestamp zerado são recalculados na próxima leitura do dia
+
+-- Alertas gerados pelas regras de alertas.json. Pendentes e disparados são
+-- recarregados ao iniciar; pendentes que não chegam a disparar são removidos
+CREATE TABLE IF NOT EXISTS alerts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    rule_id VARCHAR(64) NOT NULL,
+    rule_name VARCHAR(255) NOT NULL,
+    station VARCHAR(64) NOT NULL,
+    state VARCHAR(16) NOT NULL, -- pending, firing, resolved
+    severity VARCHAR(16) NOT NULL,
+    metric VARCHAR(64) NOT NULL,
+    value FLOAT NOT NULL,
+    threshold FLOAT NOT NULL,
+    started_at BIGINT NOT NULL,
+    fired_at BIGINT NULL,
+    resolved_at BIGINT NULL,
+    updated_at BIGINT NOT NULL,
+    KEY idx_alerts_state (state),
+    KEY idx_alerts_rule_station (rule_id, station)
+);
+
+-- Estações que já enviaram dados e o horário da última leitura recebida
+CREATE TABLE IF NOT EXISTS stations (
+    id VARCHAR(64) PRIMARY KEY,
+    first_seen BIGINT NOT NULL,
+    last_seen BIGINT NOT NULL
 );